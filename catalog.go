@@ -0,0 +1,136 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import "golang.org/x/text/feature/plural"
+
+// catalog is an immutable snapshot of the loaded languages. A reload
+// builds a new catalog and swaps it in as a whole, so readers never see
+// a partially updated catalog and need no locking.
+type catalog struct {
+	langs   map[string]map[string]string // Map of language codes to their message maps
+	origins map[string]map[string]string // Name of the source of each message, keyed like langs
+	matcher *langMatcher                 // BCP 47 matcher for the loaded languages
+	icu     map[string]icuMessage        // Parsed ICU patterns keyed by message
+}
+
+// langList returns the language list of the active catalog. The returned
+// maps are shared and must not be modified.
+//
+// Returns:
+//   - map[string]map[string]string: A map of language codes to their message maps
+func (m *Manager) langList() map[string]map[string]string {
+	return m.catalog.Load().langs
+}
+
+// langMatcher returns the BCP 47 matcher of the active catalog.
+//
+// Returns:
+//   - *langMatcher: The matcher for the loaded languages
+func (m *Manager) langMatcher() *langMatcher {
+	return m.catalog.Load().matcher
+}
+
+// LangList returns a copy of the loaded messages, keyed by language code
+// and then by message code. Modifying the copy does not affect the Manager.
+//
+// Returns:
+//   - map[string]map[string]string: A map of language codes to their message maps
+//
+// Example:
+//
+//	for lang, messages := range manager.LangList() {
+//	    fmt.Printf("%s: %d messages\n", lang, len(messages))
+//	}
+func (m *Manager) LangList() map[string]map[string]string {
+	langs := m.langList()
+	list := make(map[string]map[string]string, len(langs))
+	for lang := range langs {
+		list[lang] = m.Messages(lang)
+	}
+
+	return list
+}
+
+// Messages returns a copy of the loaded messages of a language, keyed by
+// message code, or nil if the language is not loaded.
+//
+// Parameters:
+//   - lang: The language code
+//
+// Returns:
+//   - map[string]string: The messages of the language
+//
+// Example:
+//
+//	messages := manager.Messages("en-US")
+func (m *Manager) Messages(lang string) map[string]string {
+	messages, ok := m.langList()[lang]
+	if !ok {
+		return nil
+	}
+
+	cp := make(map[string]string, len(messages))
+	for code, msg := range messages {
+		cp[code] = msg
+	}
+
+	return cp
+}
+
+// Origin returns the name of the source a loaded message came from, which
+// is the highest precedence source defining it (see WithSources). For a
+// plural message, the source of its "other" form is returned unless a
+// form such as "1001.one" is asked for.
+//
+// Parameters:
+//   - lang: The language code
+//   - code: The message code
+//
+// Returns:
+//   - string: The name of the source, e.g. the language directory
+//   - bool: false if the message is not loaded
+//
+// Example:
+//
+//	if src, ok := manager.Origin("en-US", "500"); ok {
+//	    log.Printf("message 500 comes from %s", src)
+//	}
+func (m *Manager) Origin(lang string, code string) (string, bool) {
+	origins := m.catalog.Load().origins[lang]
+	if src, ok := origins[code]; ok {
+		return src, true
+	}
+
+	src, ok := origins[code+pluralSep+pluralForms[plural.Other]]
+	return src, ok
+}
+
+// DefaultLang returns the current default language.
+//
+// Returns:
+//   - string: The default language code
+//
+// Example:
+//
+//	lang := manager.DefaultLang()
+func (m *Manager) DefaultLang() string {
+	return *m.defaultLang.Load()
+}
+
+// RunEnv returns the running environment read from the environment
+// variable configured with WithEnvKey.
+//
+// Returns:
+//   - string: The current running environment
+//
+// Example:
+//
+//	if manager.RunEnv() == "prod" {
+//	    // ...
+//	}
+func (m *Manager) RunEnv() string {
+	return m.runEnv
+}
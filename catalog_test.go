@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestAccessors(t *testing.T) {
+	t.Setenv("RUN_MODE", "test")
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{"1": "one"}`,
+		"zh-CN.json": `{"1": "一"}`,
+	})
+
+	assert.Equal(t, "test", m.RunEnv())
+	assert.Equal(t, "en-US", m.DefaultLang())
+	m.SetLang("zh-CN")
+	assert.Equal(t, "zh-CN", m.DefaultLang())
+	m.SetLang("")
+	assert.Equal(t, "zh-CN", m.DefaultLang())
+
+	list := m.LangList()
+	assert.Equal(t, map[string]map[string]string{"en-US": {"1": "one"}, "zh-CN": {"1": "一"}}, list)
+	assert.Nil(t, m.Messages("fr-FR"))
+
+	// Copies don't leak into the Manager
+	list["en-US"]["1"] = "changed"
+	m.Messages("en-US")["1"] = "changed"
+	assert.Equal(t, "one", m.Trans("en-US", "1"))
+}
+
+// TestConcurrentUse is meant to be run with the race detector: go test -race
+func TestConcurrentUse(t *testing.T) {
+	dir := writeLangDir(t, map[string]string{
+		"en-US.json": `{"1": "Hello, %s", "2": {"one": "%s file", "other": "%s files"}, "3": "Hi {name}"}`,
+		"zh-CN.json": `{"1": "你好, %s", "3": "你好 {name}"}`,
+	})
+	m, err := New(WithLangDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		m.JSON(c, 1, Data{Params: []string{"Seakee"}}, nil)
+	})
+
+	var wg sync.WaitGroup
+	run := func(n int, f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				f(i)
+			}
+		}()
+	}
+
+	run(200, func(i int) {
+		msg := m.Trans("zh-CN", "1", "Seakee")
+		assert.Contains(t, []string{"你好, Seakee", "Hello, Seakee", "您好, Seakee"}, msg)
+	})
+	run(200, func(i int) {
+		m.TransPlural("en-US", "2", i, "x")
+		m.TransMap("zh-CN", "3", map[string]interface{}{"name": "Seakee"})
+		m.Lang()
+		m.Count()
+		m.LangExist("zh-CN")
+		m.LangList()
+	})
+	run(200, func(i int) {
+		if i%2 == 0 {
+			m.SetLang("zh-CN")
+		} else {
+			m.SetLang("en-US")
+		}
+	})
+	run(200, func(i int) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Language", "zh-CN,en;q=0.5")
+		r.ServeHTTP(w, req)
+	})
+	run(20, func(i int) {
+		content := `{"1": "你好, %s", "3": "你好 {name}"}`
+		if i%2 == 0 {
+			content = `{"1": "您好, %s", "3": "您好 {name}"}`
+		}
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "zh-CN.json"), []byte(content), 0o644))
+		assert.NoError(t, m.Reload())
+	})
+
+	wg.Wait()
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"encoding/json"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"strings"
+)
+
+// decoder decodes the content of a language file into a map of message
+// codes to messages or plural objects. lang is the language of the file,
+// which formats such as gettext need to map plural forms.
+type decoder func(lang string, data []byte, v *map[string]interface{}) error
+
+// decoders maps the supported language file extensions to their decoders
+var decoders = map[string]decoder{
+	".json": func(_ string, data []byte, v *map[string]interface{}) error { return json.Unmarshal(data, v) },
+	".yaml": func(_ string, data []byte, v *map[string]interface{}) error { return yaml.Unmarshal(data, v) },
+	".yml":  func(_ string, data []byte, v *map[string]interface{}) error { return yaml.Unmarshal(data, v) },
+	".toml": func(_ string, data []byte, v *map[string]interface{}) error { return toml.Unmarshal(data, v) },
+	".po":   decodePO,
+	".mo":   decodeMO,
+}
+
+// decoderFor returns the decoder for a language file name, based on its
+// extension. Extensions are matched case-insensitively.
+//
+// Parameters:
+//   - name: The file name
+//
+// Returns:
+//   - decoder: The decoder for the file
+//   - bool: false if the file is not a supported language file
+func decoderFor(name string) (decoder, bool) {
+	d, ok := decoders[strings.ToLower(filepath.Ext(name))]
+	return d, ok
+}
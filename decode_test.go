@@ -0,0 +1,52 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDecodeFormats(t *testing.T) {
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{"0": "ok"}`,
+		"zh-CN.yaml": "# 通用响应\n0: 成功\n-1: 系统繁忙\n1001:\n  one: 一条消息\n  other: |-\n    %s 条\n    消息\n",
+		"fr-FR.yml":  "0: d'accord\n",
+		"ja-JP.toml": "# 共通\n0 = \"成功\"\n-1 = \"混雑\"\n\n[1001]\nother = \"%s 件\"\n",
+		"README.md":  "# Language files\n",
+		"notes.txt":  "not a language file",
+	}, WithDefaultLang("en-US"))
+
+	assert.ElementsMatch(t, []string{"en-US", "zh-CN", "fr-FR", "ja-JP"}, m.Lang())
+	assert.Equal(t, "成功", m.Trans("zh-CN", "0"))
+	assert.Equal(t, "系统繁忙", m.Trans("zh-CN", "-1"))
+	assert.Equal(t, "3 条\n消息", m.TransPlural("zh-CN", "1001", 3, "3"))
+	assert.Equal(t, "d'accord", m.Trans("fr-FR", "0"))
+	assert.Equal(t, "混雑", m.Trans("ja-JP", "-1"))
+	assert.Equal(t, "2 件", m.TransPlural("ja-JP", "1001", 2, "2"))
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"en-US.yaml": "0: [unclosed",
+		"en-US.toml": "0 = ",
+		"en-US.json": `{"0": }`,
+	} {
+		_, err := New(WithLangDir(writeLangDir(t, map[string]string{name: content})))
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), name)
+		}
+	}
+
+	// A directory without any language file is still an error
+	_, err := New(WithLangDir(writeLangDir(t, map[string]string{"README.md": "# empty"})))
+	assert.Error(t, err)
+}
+
+func TestDecoderFor(t *testing.T) {
+	for name, want := range map[string]bool{
+		"en.json": true, "en.JSON": true, "en.yaml": true, "en.yml": true,
+		"en.toml": true, "en": false, "en.txt": false, "en.json.bak": false,
+	} {
+		_, ok := decoderFor(name)
+		assert.Equal(t, want, ok, name)
+	}
+}
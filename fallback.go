@@ -0,0 +1,78 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import "strings"
+
+// WithFallbacks returns an Option that sets per-language fallback chains.
+// When a language is not loaded, or a key is missing from it, the languages
+// in its chain are tried in order before the default language. Languages
+// without a configured chain fall back to their parent tags, e.g.
+// "pt-BR" falls back to "pt" and then to the default language.
+//
+// Parameters:
+//   - fallbacks: A map of language codes to their ordered fallback languages
+//
+// Returns:
+//   - Option: A function that sets the fallback chains in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithFallbacks(map[string][]string{
+//	    "zh-HK": {"zh-TW", "zh-CN"},
+//	}))
+func WithFallbacks(fallbacks map[string][]string) Option {
+	return func(o *option) {
+		o.fallbacks = fallbacks
+	}
+}
+
+// fallbackChain returns the ordered list of languages to try when
+// translating into lang: lang itself, then its configured fallback chain
+// (or its parent tags if none is configured), then the default language.
+// Duplicate entries are removed.
+//
+// Parameters:
+//   - lang: The requested language code
+//
+// Returns:
+//   - []string: The languages to try, in order
+func (m *Manager) fallbackChain(lang string) []string {
+	next, ok := m.opt.fallbacks[lang]
+	if !ok {
+		next = parentLangs(lang)
+	}
+
+	chain := make([]string, 0, len(next)+2)
+	seen := make(map[string]bool, len(next)+2)
+	for _, l := range append(append([]string{lang}, next...), m.DefaultLang()) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			chain = append(chain, l)
+		}
+	}
+
+	return chain
+}
+
+// parentLangs returns the parent tags of a language code from the most
+// to the least specific, e.g. "zh-Hant-TW" returns ["zh-Hant", "zh"].
+//
+// Parameters:
+//   - lang: The language code
+//
+// Returns:
+//   - []string: The parent language codes
+func parentLangs(lang string) []string {
+	var parents []string
+	for {
+		i := strings.LastIndex(lang, "-")
+		if i <= 0 {
+			return parents
+		}
+		lang = lang[:i]
+		parents = append(parents, lang)
+	}
+}
@@ -0,0 +1,43 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFallbackChain(t *testing.T) {
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{"1": "one", "2": "two", "3": "three", "4": "four"}`,
+		"zh-CN.json": `{"1": "一", "2": "二"}`,
+		"zh-TW.json": `{"1": "壹"}`,
+		"pt.json":    `{"1": "um", "2": "dois"}`,
+		"pt-BR.json": `{"1": "um (BR)"}`,
+	}, WithFallbacks(map[string][]string{
+		"zh-HK": {"zh-TW", "zh-CN"},
+	}))
+
+	assert.Equal(t, []string{"zh-HK", "zh-TW", "zh-CN", "en-US"}, m.fallbackChain("zh-HK"))
+	assert.Equal(t, []string{"pt-BR", "pt", "en-US"}, m.fallbackChain("pt-BR"))
+	assert.Equal(t, []string{"en-US", "en"}, m.fallbackChain("en-US"))
+
+	tests := []struct {
+		lang, code   string
+		want, served string
+	}{
+		{lang: "zh-HK", code: "1", want: "壹", served: "zh-TW"},
+		{lang: "zh-HK", code: "2", want: "二", served: "zh-CN"},
+		{lang: "zh-HK", code: "3", want: "three", served: "en-US"},
+		{lang: "zh-TW", code: "2", want: "two", served: "en-US"},
+		{lang: "pt-BR", code: "1", want: "um (BR)", served: "pt-BR"},
+		{lang: "pt-BR", code: "2", want: "dois", served: "pt"},
+		{lang: "pt-BR", code: "4", want: "four", served: "en-US"},
+		{lang: "fr-FR", code: "1", want: "one", served: "en-US"},
+		{lang: "zh-HK", code: "404", want: "404", served: ""},
+	}
+
+	for _, tt := range tests {
+		msg, served := m.TransWithLang(tt.lang, tt.code)
+		assert.Equal(t, tt.want, msg, tt.lang+"/"+tt.code)
+		assert.Equal(t, tt.served, served, tt.lang+"/"+tt.code)
+	}
+}
@@ -0,0 +1,40 @@
+package i18n
+
+import (
+	"embed"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+//go:embed lang
+var testLangFS embed.FS
+
+func TestWithFS(t *testing.T) {
+	m, err := New(WithFS(testLangFS, "lang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.ElementsMatch(t, []string{"en-US", "zh-CN"}, m.Lang())
+	assert.Equal(t, "Hello,Seakee!Your account is:188", m.Trans("en-US", "1000", "Seakee", "188"))
+
+	m, err = New(WithFS(fstest.MapFS{
+		"i18n/fr-FR.json": {Data: []byte(`{"1": "un"}`)},
+		"other/de.json":   {Data: []byte(`{"1": "eins"}`)},
+	}, "i18n"), WithDefaultLang("fr-FR"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"fr-FR"}, m.Lang())
+	assert.Equal(t, "un", m.Trans("de", "1"))
+
+	// The last of WithLangDir and WithFS wins
+	m, err = New(WithFS(fstest.MapFS{}, "."), WithLangDir("./lang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, m.Count())
+
+	_, err = New(WithFS(fstest.MapFS{}, "missing"))
+	assert.Error(t, err)
+}
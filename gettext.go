@@ -0,0 +1,576 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"golang.org/x/text/feature/plural"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultPluralForms are the plural forms of a gettext catalog without
+	// a Plural-Forms header, as defined by gettext
+	defaultPluralForms = "nplurals=2; plural=(n != 1);"
+
+	// moMagic is the magic number of a gettext MO file
+	moMagic = 0x950412de
+)
+
+type (
+	// gettextEntry is a message of a gettext catalog.
+	gettextEntry struct {
+		ctxt    string   // Message context
+		hasCtxt bool     // Whether the message has a context, which may be empty
+		id      string   // Message ID, used as the message code
+		plural  bool     // Whether the message has plural forms
+		strs    []string // Translations, one per plural form
+		fuzzy   bool     // Whether the translation is marked fuzzy
+	}
+
+	// pluralExpr computes the index of the plural form for a count.
+	pluralExpr func(n int) int
+
+	// pluralExprParser parses the plural expression of a Plural-Forms header.
+	pluralExprParser struct {
+		src string
+		pos int
+	}
+)
+
+// decodePO decodes a gettext PO file into the content of a language file.
+// Fuzzy, obsolete and untranslated messages are skipped, and plural forms
+// are mapped to the CLDR plural categories of lang (see gettextMessages).
+//
+// Parameters:
+//   - lang: The language of the file
+//   - data: The content of the file
+//   - v: The map to decode the messages into
+//
+// Returns:
+//   - error: An error if the file is malformed
+func decodePO(lang string, data []byte, v *map[string]interface{}) error {
+	var (
+		entries []gettextEntry
+		cur     gettextEntry
+		hasID   bool
+		done    bool    // Whether cur has a translation, so a new keyword starts a new entry
+		field   *string // The string continuation lines are appended to
+	)
+	flush := func() {
+		if hasID {
+			entries = append(entries, cur)
+		}
+		cur, hasID, done, field = gettextEntry{}, false, false, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			flush()
+			continue
+		case strings.HasPrefix(line, "#"):
+			if done {
+				flush()
+			}
+			// Obsolete messages ("#~") and other comments are ignored
+			if strings.HasPrefix(line, "#,") && strings.Contains(line, "fuzzy") {
+				cur.fuzzy = true
+			}
+			continue
+		case strings.HasPrefix(line, `"`):
+			if field == nil {
+				return fmt.Errorf("line %d: unexpected string", lineNo)
+			}
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid string %s", lineNo, line)
+			}
+			*field += s
+			continue
+		}
+
+		keyword, rest, _ := strings.Cut(line, " ")
+		s, err := strconv.Unquote(strings.TrimSpace(rest))
+		if err != nil {
+			return fmt.Errorf("line %d: invalid string %s", lineNo, strings.TrimSpace(rest))
+		}
+
+		switch {
+		case keyword == "msgctxt":
+			if done || hasID {
+				flush()
+			}
+			cur.ctxt, cur.hasCtxt = s, true
+			field = &cur.ctxt
+		case keyword == "msgid":
+			if done || hasID {
+				flush()
+			}
+			cur.id, hasID = s, true
+			field = &cur.id
+		case keyword == "msgid_plural":
+			// The plural msgid is only the source text of the plural forms
+			cur.plural = true
+			field = new(string)
+		case keyword == "msgstr" || strings.HasPrefix(keyword, "msgstr["):
+			i := 0
+			if keyword != "msgstr" {
+				i, err = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(keyword, "msgstr["), "]"))
+				if err != nil || i < 0 || !strings.HasSuffix(keyword, "]") {
+					return fmt.Errorf("line %d: invalid keyword %s", lineNo, keyword)
+				}
+			}
+			for len(cur.strs) <= i {
+				cur.strs = append(cur.strs, "")
+			}
+			cur.strs[i] = s
+			field = &cur.strs[i]
+			done = true
+		default:
+			return fmt.Errorf("line %d: invalid keyword %s", lineNo, keyword)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	flush()
+
+	messages, err := gettextMessages(lang, entries)
+	if err != nil {
+		return err
+	}
+	*v = messages
+
+	return nil
+}
+
+// decodeMO decodes a gettext MO file into the content of a language file.
+// Both byte orders are supported, and plural forms are mapped to the CLDR
+// plural categories of lang (see gettextMessages).
+//
+// Parameters:
+//   - lang: The language of the file
+//   - data: The content of the file
+//   - v: The map to decode the messages into
+//
+// Returns:
+//   - error: An error if the file is malformed
+func decodeMO(lang string, data []byte, v *map[string]interface{}) error {
+	if len(data) < 20 {
+		return errors.New("invalid MO file: too short")
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(data) != moMagic {
+		order = binary.BigEndian
+		if order.Uint32(data) != moMagic {
+			return errors.New("invalid MO file: bad magic number")
+		}
+	}
+	if major := order.Uint32(data[4:]) >> 16; major > 1 {
+		return fmt.Errorf("unsupported MO file revision %d", major)
+	}
+
+	n, origs, trans := order.Uint32(data[8:]), order.Uint32(data[12:]), order.Uint32(data[16:])
+	if uint64(n)*8 > uint64(len(data)) {
+		return errors.New("invalid MO file: string table out of range")
+	}
+	str := func(table uint32, i uint32) (string, error) {
+		at := uint64(table) + 8*uint64(i)
+		if at+8 > uint64(len(data)) {
+			return "", errors.New("invalid MO file: string table out of range")
+		}
+		length, offset := uint64(order.Uint32(data[at:])), uint64(order.Uint32(data[at+4:]))
+		if offset+length > uint64(len(data)) {
+			return "", errors.New("invalid MO file: string out of range")
+		}
+		return string(data[offset : offset+length]), nil
+	}
+
+	entries := make([]gettextEntry, 0, n)
+	for i := uint32(0); i < n; i++ {
+		orig, err := str(origs, i)
+		if err != nil {
+			return err
+		}
+		tran, err := str(trans, i)
+		if err != nil {
+			return err
+		}
+
+		var e gettextEntry
+		if ctxt, id, ok := strings.Cut(orig, "\x04"); ok {
+			e.ctxt, e.hasCtxt, orig = ctxt, true, id
+		}
+		e.id, _, e.plural = strings.Cut(orig, "\x00")
+		e.strs = strings.Split(tran, "\x00")
+		entries = append(entries, e)
+	}
+
+	messages, err := gettextMessages(lang, entries)
+	if err != nil {
+		return err
+	}
+	*v = messages
+
+	return nil
+}
+
+// gettextMessages converts the messages of a gettext catalog into the
+// content of a language file. The msgid is the message code, and messages
+// with a msgctxt are nested in an object keyed by the context, so they are
+// translated as e.g. "admin.1000" (see WithKeySeparator). Plural forms
+// are mapped to CLDR plural categories by evaluating the Plural-Forms
+// expression of the catalog header (see pluralCategories).
+//
+// Parameters:
+//   - lang: The language of the catalog
+//   - entries: The messages of the catalog, including its header
+//
+// Returns:
+//   - map[string]interface{}: The messages and plural objects keyed by code
+//   - error: An error if the Plural-Forms header is invalid, or a context
+//     is also a msgid
+func gettextMessages(lang string, entries []gettextEntry) (map[string]interface{}, error) {
+	header := defaultPluralForms
+	for _, e := range entries {
+		if e.id == "" && !e.hasCtxt && len(e.strs) > 0 {
+			header = gettextHeader(e.strs[0], "Plural-Forms", defaultPluralForms)
+		}
+	}
+
+	categories, err := pluralCategories(lang, header)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(map[string]interface{}, len(entries))
+	contexts := make(map[string]bool)
+	for _, e := range entries {
+		// Skip the header, fuzzy and untranslated messages
+		if (e.id == "" && !e.hasCtxt) || e.fuzzy || len(e.strs) == 0 {
+			continue
+		}
+
+		// Messages with a context go to the namespace object of the context
+		dst := messages
+		if !e.hasCtxt && contexts[e.id] {
+			return nil, fmt.Errorf("msgctxt %q is also a msgid", e.id)
+		}
+		if e.hasCtxt {
+			ns, ok := messages[e.ctxt].(map[string]interface{})
+			if !ok {
+				if _, found := messages[e.ctxt]; found {
+					return nil, fmt.Errorf("msgctxt %q is also a msgid", e.ctxt)
+				}
+				ns = make(map[string]interface{})
+				messages[e.ctxt] = ns
+				contexts[e.ctxt] = true
+			}
+			dst = ns
+		}
+
+		if !e.plural {
+			if e.strs[0] != "" {
+				dst[e.id] = e.strs[0]
+			}
+			continue
+		}
+
+		forms := make(map[string]interface{}, len(e.strs)+1)
+		for i, category := range categories {
+			if category != "" && i < len(e.strs) && e.strs[i] != "" {
+				forms[category] = e.strs[i]
+			}
+		}
+		// gettext has no form for fractions, which CLDR often puts in
+		// "other", so the last form serves for them if no form took it
+		if last := e.strs[len(e.strs)-1]; forms[pluralForms[plural.Other]] == nil && last != "" {
+			forms[pluralForms[plural.Other]] = last
+		}
+		if len(forms) > 0 {
+			dst[e.id] = forms
+		}
+	}
+
+	return messages, nil
+}
+
+// gettextHeader returns the value of a field of a gettext catalog header.
+//
+// Parameters:
+//   - header: The catalog header, the translation of the empty msgid
+//   - name: The name of the field
+//   - def: The value to return if the field is missing
+//
+// Returns:
+//   - string: The value of the field
+func gettextHeader(header string, name string, def string) string {
+	for _, line := range strings.Split(header, "\n") {
+		if k, v, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(k), name) {
+			return strings.TrimSpace(v)
+		}
+	}
+
+	return def
+}
+
+// pluralCategories maps the plural forms of a gettext catalog to the CLDR
+// plural categories of its language. Each form takes the category of the
+// first count it is selected for whose category no earlier form took.
+//
+// Parameters:
+//   - lang: The language of the catalog
+//   - header: The value of the Plural-Forms header
+//
+// Returns:
+//   - []string: The plural category of each form, "" if it has none
+//   - error: An error if the header is invalid
+func pluralCategories(lang string, header string) ([]string, error) {
+	var nplurals int
+	var expr pluralExpr
+	for _, field := range strings.Split(header, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch strings.TrimSpace(name) {
+		case "nplurals":
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid Plural-Forms %q: bad nplurals", header)
+			}
+			nplurals = n
+		case "plural":
+			var err error
+			if expr, err = parsePluralExpr(value); err != nil {
+				return nil, fmt.Errorf("invalid Plural-Forms %q: %w", header, err)
+			}
+		}
+	}
+	if nplurals == 0 || expr == nil {
+		return nil, fmt.Errorf("invalid Plural-Forms %q: nplurals and plural are required", header)
+	}
+
+	categories := make([]string, nplurals)
+	taken := make(map[string]bool, nplurals)
+	for n := 0; n <= 1000; n++ {
+		i := expr(n)
+		category := pluralCategory(lang, n)
+		if i < 0 || i >= nplurals || categories[i] != "" || taken[category] {
+			continue
+		}
+		categories[i] = category
+		taken[category] = true
+	}
+
+	return categories, nil
+}
+
+// parsePluralExpr parses the C expression of a Plural-Forms header, such
+// as "(n != 1)", into a function that evaluates it.
+//
+// Parameters:
+//   - src: The expression
+//
+// Returns:
+//   - pluralExpr: The compiled expression
+//   - error: An error if the expression is invalid
+func parsePluralExpr(src string) (pluralExpr, error) {
+	p := &pluralExprParser{src: src}
+	expr, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.src[p.pos:], p.pos)
+	}
+
+	return expr, nil
+}
+
+// ternary parses a conditional expression, the lowest precedence level.
+func (p *pluralExprParser) ternary() (pluralExpr, error) {
+	cond, err := p.binary(0)
+	if err != nil || !p.accept("?") {
+		return cond, err
+	}
+
+	then, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(":") {
+		return nil, fmt.Errorf("missing ':' at offset %d", p.pos)
+	}
+	els, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(n int) int {
+		if cond(n) != 0 {
+			return then(n)
+		}
+		return els(n)
+	}, nil
+}
+
+// pluralBinaryOps lists the binary operators of plural expressions by
+// increasing precedence. Longer operators come first within a level so
+// "<=" is not read as "<".
+var pluralBinaryOps = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<=", ">=", "<", ">"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// binary parses the binary operators of a precedence level and above.
+func (p *pluralExprParser) binary(level int) (pluralExpr, error) {
+	if level == len(pluralBinaryOps) {
+		return p.unary()
+	}
+
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := ""
+		for _, candidate := range pluralBinaryOps[level] {
+			if p.accept(candidate) {
+				op = candidate
+				break
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = pluralBinary(op, left, right)
+	}
+}
+
+// unary parses a negation, a parenthesized expression, n or a number.
+func (p *pluralExprParser) unary() (pluralExpr, error) {
+	switch {
+	case p.accept("!"):
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(n int) int { return boolInt(operand(n) == 0) }, nil
+	case p.accept("("):
+		expr, err := p.ternary()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ')' at offset %d", p.pos)
+		}
+		return expr, nil
+	case p.accept("n"):
+		return func(n int) int { return n }, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.src[p.pos:], p.pos)
+	}
+	value, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return nil, err
+	}
+
+	return func(int) int { return value }, nil
+}
+
+// accept skips spaces and consumes tok if the input continues with it.
+func (p *pluralExprParser) accept(tok string) bool {
+	p.skipSpace()
+	if !strings.HasPrefix(p.src[p.pos:], tok) {
+		return false
+	}
+	// "!" must not consume the start of "!="
+	if tok == "!" && strings.HasPrefix(p.src[p.pos:], "!=") {
+		return false
+	}
+
+	p.pos += len(tok)
+	return true
+}
+
+// skipSpace skips whitespace.
+func (p *pluralExprParser) skipSpace() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// pluralBinary returns a function applying a binary operator. Division by
+// zero yields 0 instead of panicking.
+func pluralBinary(op string, left pluralExpr, right pluralExpr) pluralExpr {
+	return func(n int) int {
+		a, b := left(n), right(n)
+		switch op {
+		case "||":
+			return boolInt(a != 0 || b != 0)
+		case "&&":
+			return boolInt(a != 0 && b != 0)
+		case "==":
+			return boolInt(a == b)
+		case "!=":
+			return boolInt(a != b)
+		case "<=":
+			return boolInt(a <= b)
+		case ">=":
+			return boolInt(a >= b)
+		case "<":
+			return boolInt(a < b)
+		case ">":
+			return boolInt(a > b)
+		case "+":
+			return a + b
+		case "-":
+			return a - b
+		case "*":
+			return a * b
+		case "/", "%":
+			if b == 0 {
+				return 0
+			}
+			if op == "/" {
+				return a / b
+			}
+			return a % b
+		}
+		return 0
+	}
+}
+
+// boolInt converts a boolean to the integer C would use.
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
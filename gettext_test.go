@@ -0,0 +1,190 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+)
+
+const testPO = `# Russian translation
+#, fuzzy
+msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && "
+"n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "0"
+msgstr "успех"
+
+#: handlers/user.go:42
+msgid "1000"
+msgstr ""
+"Привет, %s!\n"
+"Ваш аккаунт: %s"
+
+msgid "1001"
+msgid_plural "1001"
+msgstr[0] "%s сообщение"
+msgstr[1] "%s сообщения"
+msgstr[2] "%s сообщений"
+
+msgctxt "admin"
+msgid "0"
+msgstr "готово"
+
+#, fuzzy
+msgid "500"
+msgstr "ошибка?"
+
+msgid "400"
+msgstr ""
+
+#~ msgid "-1"
+#~ msgstr "занято"
+`
+
+func TestGettextPO(t *testing.T) {
+	m := newTestManager(t, map[string]string{"ru.po": testPO}, WithDefaultLang("ru"))
+
+	assert.Equal(t, "успех", m.Trans("ru", "0"))
+	assert.Equal(t, "Привет, Seakee!\nВаш аккаунт: 188", m.Trans("ru", "1000", "Seakee", "188"))
+	assert.Equal(t, "1 сообщение", m.TransPlural("ru", "1001", 1, "1"))
+	assert.Equal(t, "3 сообщения", m.TransPlural("ru", "1001", 3, "3"))
+	assert.Equal(t, "5 сообщений", m.TransPlural("ru", "1001", 5, "5"))
+	assert.Equal(t, "21 сообщение", m.TransPlural("ru", "1001", 21, "21"))
+	assert.Equal(t, "1.5 сообщений", m.TransPlural("ru", "1001", 1.5, "1.5"))
+	assert.Equal(t, "готово", m.Trans("ru", "admin.0"))
+
+	// Fuzzy, untranslated and obsolete messages are skipped
+	assert.Equal(t, "500", m.Trans("ru", "500"))
+	assert.Equal(t, "400", m.Trans("ru", "400"))
+	assert.Equal(t, "-1", m.Trans("ru", "-1"))
+}
+
+func TestGettextMO(t *testing.T) {
+	messages := map[string]string{
+		"":                 "Plural-Forms: nplurals=2; plural=(n > 1);\n",
+		"0":                "succès",
+		"1001\x001001":     "%s message\x00%s messages",
+		"admin\x040":       "terminé",
+		"400":              "",
+		"1000\x00singular": "\x00",
+	}
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		m := newTestManager(t, map[string]string{"fr.mo": string(buildMO(order, messages))}, WithDefaultLang("fr"))
+
+		assert.Equal(t, "succès", m.Trans("fr", "0"))
+		assert.Equal(t, "0 message", m.TransPlural("fr", "1001", 0, "0"))
+		assert.Equal(t, "1 message", m.TransPlural("fr", "1001", 1, "1"))
+		assert.Equal(t, "2 messages", m.TransPlural("fr", "1001", 2, "2"))
+		assert.Equal(t, "terminé", m.Trans("fr", "admin.0"))
+		assert.Equal(t, "400", m.Trans("fr", "400"))
+		assert.Equal(t, "1000", m.Trans("fr", "1000"))
+	}
+
+	for _, data := range [][]byte{
+		[]byte("short"),
+		bytes.Repeat([]byte{0xff}, 28),
+		buildMO(binary.LittleEndian, messages)[:40],
+	} {
+		var v map[string]interface{}
+		assert.Error(t, decodeMO("fr", data, &v))
+	}
+}
+
+func TestGettextErrors(t *testing.T) {
+	for _, po := range []string{
+		"msgid \"0\"\nmsgstr \"unterminated\n",
+		"\"orphan string\"\n",
+		"msgid \"0\"\nmsgstr[x] \"bad index\"\n",
+		"msgid \"0\"\nmsgfoo \"bad keyword\"\n",
+		"msgid \"\"\nmsgstr \"Plural-Forms: nplurals=2; plural=(n != ;\\n\"\n",
+		"msgid \"\"\nmsgstr \"Plural-Forms: plural=(n != 1);\\n\"\n",
+	} {
+		var v map[string]interface{}
+		assert.Error(t, decodePO("en", []byte(po), &v), po)
+	}
+}
+
+func TestPluralCategories(t *testing.T) {
+	tests := []struct {
+		lang   string
+		header string
+		want   []string
+	}{
+		{"en", defaultPluralForms, []string{"one", "other"}},
+		{"fr", "nplurals=2; plural=(n > 1);", []string{"one", "other"}},
+		{"ja", "nplurals=1; plural=0;", []string{"other"}},
+		{"ru", "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);", []string{"one", "few", "many"}},
+		{"ar", "nplurals=6; plural=(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5);", []string{"zero", "one", "two", "few", "many", "other"}},
+	}
+
+	for _, tt := range tests {
+		got, err := pluralCategories(tt.lang, tt.header)
+		if assert.NoError(t, err, tt.lang) {
+			assert.Equal(t, tt.want, got, tt.lang)
+		}
+	}
+}
+
+func TestParsePluralExpr(t *testing.T) {
+	tests := map[string][]int{
+		"n != 1":              {1, 0, 1, 1},
+		"!(n == 1)":           {1, 0, 1, 1},
+		"n > 1 ? 1 : 0":       {0, 0, 1, 1},
+		"(n + 1) * 2 / 3 % 2": {0, 1, 0, 0},
+		"n / 0":               {0, 0, 0, 0},
+		"n <= 1 || n >= 3":    {1, 1, 0, 1},
+	}
+
+	for src, want := range tests {
+		expr, err := parsePluralExpr(src)
+		if !assert.NoError(t, err, src) {
+			continue
+		}
+		for n, w := range want {
+			assert.Equal(t, w, expr(n), "%s with n=%d", src, n)
+		}
+	}
+
+	for _, src := range []string{"", "n ?", "n ? 1", "(n", "n 1", "x"} {
+		_, err := parsePluralExpr(src)
+		assert.Error(t, err, src)
+	}
+}
+
+// buildMO encodes messages, keyed by their original string, as an MO file.
+func buildMO(order binary.ByteOrder, messages map[string]string) []byte {
+	origs := make([]string, 0, len(messages))
+	for orig := range messages {
+		origs = append(origs, orig)
+	}
+	sort.Strings(origs)
+
+	n := uint32(len(origs))
+	origTable, transTable := uint32(28), 28+8*n
+	offset := transTable + 8*n
+
+	var header, tables, strs bytes.Buffer
+	for _, v := range []uint32{moMagic, 0, n, origTable, transTable, 0, 0} {
+		_ = binary.Write(&header, order, v)
+	}
+
+	for _, table := range []func(string) string{
+		func(orig string) string { return orig },
+		func(orig string) string { return messages[orig] },
+	} {
+		for _, orig := range origs {
+			s := table(orig)
+			_ = binary.Write(&tables, order, uint32(len(s)))
+			_ = binary.Write(&tables, order, offset+uint32(strs.Len()))
+			strs.WriteString(s + "\x00")
+		}
+	}
+
+	return append(append(header.Bytes(), tables.Bytes()...), strs.Bytes()...)
+}
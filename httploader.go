@@ -0,0 +1,304 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultHTTPTimeout bounds the requests of an HTTP loader without a client
+const defaultHTTPTimeout = 10 * time.Second
+
+type (
+	// HTTPOption is a function type that modifies an HTTP loader
+	HTTPOption func(*httpLoader)
+
+	// httpLoader loads messages from a translation service over HTTP
+	httpLoader struct {
+		url       string       // URL of the catalog
+		client    *http.Client // Client sending the requests
+		cacheFile string       // File keeping the last good catalog, "" for none
+		fallback  Loader       // Loader used when neither the service nor the cache are available
+
+		mu    sync.Mutex // Guards last
+		last  *httpCache // Last good catalog, nil before the first one
+		ready bool       // Whether the cache file was read into last
+	}
+
+	// httpCache is the last good catalog of an HTTP loader, as kept in its cache file
+	httpCache struct {
+		ETag         string          `json:"etag,omitempty"`
+		LastModified string          `json:"last_modified,omitempty"`
+		Body         json.RawMessage `json:"body"`
+	}
+
+	// fsLoader loads the language files of a directory of a file system
+	fsLoader struct {
+		fsys fs.FS  // File system holding the language files
+		root string // Directory of the language files within fsys
+	}
+)
+
+// HTTPLoader returns a Loader pulling the catalog from a translation
+// service. The service must answer with a JSON object mapping language
+// codes to their messages, in the format of JSON language files with nested
+// keys joined with the key separator (see WithKeySeparator). Requests time
+// out after 10 seconds unless a client is set (see WithHTTPClient), and are
+// conditional: the ETag and Last-Modified of the last good catalog are sent
+// back, and a 304 Not Modified answer reuses it. When the service is
+// unreachable, too slow or fails, the last good
+// catalog is used, read from the cache file after a restart (see
+// WithHTTPCache), and then the fallback loader (see WithHTTPFallback).
+//
+// Parameters:
+//   - url: The URL of the catalog
+//   - opts: Options configuring the loader
+//
+// Returns:
+//   - Loader: The HTTP loader
+//
+// Example:
+//
+//	//go:embed lang
+//	var langFS embed.FS
+//
+//	loader := i18n.HTTPLoader("https://i18n.example.com/catalogs/shop",
+//	    i18n.WithHTTPCache("/var/cache/shop/i18n.json"),
+//	    i18n.WithHTTPFallback(i18n.FSLoader(langFS, "lang")),
+//	)
+//	manager, err := i18n.New(i18n.WithLoader(loader), i18n.WithRefresh(time.Minute))
+func HTTPLoader(url string, opts ...HTTPOption) Loader {
+	l := &httpLoader{url: url, client: &http.Client{Timeout: defaultHTTPTimeout}}
+	for _, f := range opts {
+		f(l)
+	}
+
+	return l
+}
+
+// WithHTTPClient returns an HTTPOption that sets the client sending the
+// requests, e.g. to set a timeout or authentication.
+//
+// Parameters:
+//   - c: The HTTP client
+//
+// Returns:
+//   - HTTPOption: A function that sets the client of the loader
+func WithHTTPClient(c *http.Client) HTTPOption {
+	return func(l *httpLoader) {
+		l.client = c
+	}
+}
+
+// WithHTTPCache returns an HTTPOption that keeps the last good catalog in
+// a file, so it survives restarts while the service is unreachable.
+//
+// Parameters:
+//   - path: The path of the cache file
+//
+// Returns:
+//   - HTTPOption: A function that sets the cache file of the loader
+func WithHTTPCache(path string) HTTPOption {
+	return func(l *httpLoader) {
+		l.cacheFile = path
+	}
+}
+
+// WithHTTPFallback returns an HTTPOption that sets the loader used when
+// the service is unreachable and there is no last good catalog, such as
+// an FSLoader of language files embedded in the binary.
+//
+// Parameters:
+//   - fallback: The fallback loader
+//
+// Returns:
+//   - HTTPOption: A function that sets the fallback loader of the loader
+func WithHTTPFallback(fallback Loader) HTTPOption {
+	return func(l *httpLoader) {
+		l.fallback = fallback
+	}
+}
+
+// FSLoader returns a Loader reading the language files of a directory of a
+// file system, such as an embed.FS. Nested keys are joined with the key
+// separator (see WithKeySeparator).
+//
+// Parameters:
+//   - fsys: The file system holding the language files
+//   - root: The directory of the language files within fsys, "." for its root
+//
+// Returns:
+//   - Loader: The file system loader
+func FSLoader(fsys fs.FS, root string) Loader {
+	return &fsLoader{fsys: fsys, root: root}
+}
+
+// Load implements Loader.
+func (l *fsLoader) Load(ctx context.Context) (map[string]map[string]string, error) {
+	return l.loadSep(ctx, defaultKeySep)
+}
+
+// loadSep implements separatedLoader.
+func (l *fsLoader) loadSep(_ context.Context, sep string) (map[string]map[string]string, error) {
+	langList, _, err := loadLangFiles(l.fsys, l.root, sep, false)
+	return langList, err
+}
+
+// Load implements Loader.
+func (l *httpLoader) Load(ctx context.Context) (map[string]map[string]string, error) {
+	return l.loadSep(ctx, defaultKeySep)
+}
+
+// loadSep implements separatedLoader.
+func (l *httpLoader) loadSep(ctx context.Context, sep string) (map[string]map[string]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Resume from the cache file after a restart
+	if !l.ready {
+		l.ready = true
+		l.last = readHTTPCache(l.cacheFile, sep)
+	}
+
+	langList, err := l.fetch(ctx, sep)
+	if err == nil {
+		return langList, nil
+	}
+
+	// Serve the last good catalog, then the fallback
+	if l.last != nil {
+		return decodeCatalog(l.last.Body, sep)
+	}
+	if l.fallback != nil {
+		return loadWithSep(ctx, l.fallback, sep)
+	}
+
+	return nil, err
+}
+
+// fetch requests the catalog from the service, conditionally on the last
+// good one, and remembers and caches it if it changed.
+//
+// Parameters:
+//   - ctx: The context of the request
+//   - sep: The separator joining nested keys
+//
+// Returns:
+//   - map[string]map[string]string: The messages keyed by language and code
+//   - error: An error if the service is unreachable or fails
+func (l *httpLoader) fetch(ctx context.Context, sep string) (map[string]map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if l.last != nil {
+		if l.last.ETag != "" {
+			req.Header.Set("If-None-Match", l.last.ETag)
+		}
+		if l.last.LastModified != "" {
+			req.Header.Set("If-Modified-Since", l.last.LastModified)
+		}
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && l.last != nil {
+		return decodeCatalog(l.last.Body, sep)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", l.url, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	langList, err := decodeCatalog(body, sep)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", l.url, err)
+	}
+
+	l.last = &httpCache{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified"), Body: body}
+	if l.cacheFile != "" {
+		// The catalog is usable even if it cannot be cached
+		_ = writeJSONFile(l.cacheFile, l.last)
+	}
+
+	return langList, nil
+}
+
+// readHTTPCache reads the last good catalog of an HTTP loader from its
+// cache file.
+//
+// Parameters:
+//   - path: The path of the cache file, "" for none
+//   - sep: The separator joining nested keys
+//
+// Returns:
+//   - *httpCache: The cached catalog, nil if there is none or it is invalid
+func readHTTPCache(path string, sep string) *httpCache {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var c httpCache
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil
+	}
+	if _, err = decodeCatalog(c.Body, sep); err != nil {
+		return nil
+	}
+
+	return &c
+}
+
+// decodeCatalog decodes a JSON object mapping language codes to the
+// content of their language files.
+//
+// Parameters:
+//   - data: The JSON catalog
+//   - sep: The separator joining nested keys
+//
+// Returns:
+//   - map[string]map[string]string: The messages keyed by language and code
+//   - error: An error if the catalog is invalid
+func decodeCatalog(data []byte, sep string) (map[string]map[string]string, error) {
+	var raw map[string]map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("empty catalog")
+	}
+
+	langList := make(map[string]map[string]string, len(raw))
+	for lang, messages := range raw {
+		parsed, err := parseMessages(messages, "", sep)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", lang, err)
+		}
+		langList[lang] = parsed
+	}
+
+	return langList, nil
+}
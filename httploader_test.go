@@ -0,0 +1,150 @@
+package i18n
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// catalogServer is a translation service stand-in serving a catalog with
+// an ETag, and recording the conditional headers it receives.
+type catalogServer struct {
+	mu       sync.Mutex
+	body     string
+	etag     string
+	fail     bool
+	requests []http.Header
+}
+
+func (s *catalogServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Header.Clone())
+	switch {
+	case s.fail:
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case r.Header.Get("If-None-Match") == s.etag:
+		w.WriteHeader(http.StatusNotModified)
+	default:
+		w.Header().Set("ETag", s.etag)
+		w.Header().Set("Last-Modified", "Wed, 01 May 2024 10:00:00 GMT")
+		_, _ = w.Write([]byte(s.body))
+	}
+}
+
+func (s *catalogServer) set(body string, etag string, fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag, s.fail = body, etag, fail
+}
+
+func TestHTTPLoader(t *testing.T) {
+	cs := &catalogServer{}
+	cs.set(`{"en-US": {"0": "ok", "user": {"login": "Login"}}, "zh-CN": {"0": "成功"}}`, `"v1"`, false)
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+
+	cache := filepath.Join(t.TempDir(), "catalog.json")
+	m, err := New(WithLoader(HTTPLoader(srv.URL, WithHTTPCache(cache), WithHTTPClient(srv.Client()))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "ok", m.Trans("en-US", "0"))
+	assert.Equal(t, "Login", m.Trans("en-US", "user.login"))
+	assert.Equal(t, "成功", m.Trans("zh-CN", "0"))
+
+	// Unchanged catalogs are not transferred again
+	assert.NoError(t, m.Reload())
+	assert.Equal(t, `"v1"`, cs.requests[1].Get("If-None-Match"))
+	assert.Equal(t, "Wed, 01 May 2024 10:00:00 GMT", cs.requests[1].Get("If-Modified-Since"))
+	assert.Equal(t, "ok", m.Trans("en-US", "0"))
+
+	cs.set(`{"en-US": {"0": "all good"}}`, `"v2"`, false)
+	assert.NoError(t, m.Reload())
+	assert.Equal(t, "all good", m.Trans("en-US", "0"))
+
+	// The last good catalog is served while the service fails
+	cs.set("", "", true)
+	assert.NoError(t, m.Reload())
+	assert.Equal(t, "all good", m.Trans("en-US", "0"))
+
+	// After a restart, the cache file is served while the service fails
+	m, err = New(WithLoader(HTTPLoader(srv.URL, WithHTTPCache(cache), WithHTTPClient(srv.Client()))))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "all good", m.Trans("en-US", "0"))
+	}
+
+	// and resumes conditional requests when it recovers
+	cs.set(`{"en-US": {"0": "all good"}}`, `"v2"`, false)
+	assert.NoError(t, m.Reload())
+	assert.Equal(t, `"v2"`, cs.requests[len(cs.requests)-1].Get("If-None-Match"))
+}
+
+func TestHTTPLoaderFallback(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	embedded := fstest.MapFS{"lang/en-US.json": {Data: []byte(`{"0": "embedded"}`)}}
+	m, err := New(WithLoader(HTTPLoader(url, WithHTTPFallback(FSLoader(embedded, "lang")))))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "embedded", m.Trans("en-US", "0"))
+	}
+
+	_, err = New(WithLoader(HTTPLoader(url)))
+	assert.Error(t, err)
+
+	// Invalid catalogs are rejected
+	for _, body := range []string{`not json`, `{}`, `{"en-US": {"0": 1}}`} {
+		cs := &catalogServer{}
+		cs.set(body, `"v1"`, false)
+		srv := httptest.NewServer(cs)
+		_, err = HTTPLoader(srv.URL).Load(context.Background())
+		assert.Error(t, err, body)
+		srv.Close()
+	}
+}
+
+func TestHTTPLoaderTimeout(t *testing.T) {
+	assert.Equal(t, defaultHTTPTimeout, HTTPLoader("http://localhost").(*httpLoader).client.Timeout)
+
+	// A service that never answers falls back once the load times out
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	embedded := fstest.MapFS{"lang/en-US.json": {Data: []byte(`{"user": {"login": "Login"}}`)}}
+	m, err := New(
+		WithLoadTimeout(50*time.Millisecond),
+		WithKeySeparator("/"),
+		WithLoader(HTTPLoader(srv.URL, WithHTTPFallback(FSLoader(embedded, "lang")))),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Login", m.Trans("en-US", "user/login"))
+	}
+}
+
+func TestHTTPLoaderKeySeparator(t *testing.T) {
+	cs := &catalogServer{}
+	cs.set(`{"en-US": {"user": {"login": "Login"}}}`, `"v1"`, false)
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+
+	m, err := New(WithKeySeparator("/"), WithLoader(HTTPLoader(srv.URL)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Login", m.Trans("en-US", "user/login"))
+	}
+}
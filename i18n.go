@@ -10,15 +10,18 @@
 package i18n
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"net/http"
+	"golang.org/x/text/feature/plural"
+	"io/fs"
+	"log/slog"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -36,17 +39,48 @@ type (
 
 	// option contains configuration settings for the i18n manager
 	option struct {
-		langDir     string // Directory path for language files
-		defaultLang string // Default language code
-		envKey      string // Environment variable key for run mode
-		debugMode   bool   // Whether debug mode is enabled
+		sources            []Source            // Layers of messages, from the lowest to the highest precedence
+		keySep             string              // Separator joining the keys of nested objects in language files into message codes
+		namespaces         bool                // Whether message codes are prefixed with namespaces derived from the file paths
+		defaultLang        string              // Default language code
+		envKey             string              // Environment variable key for run mode
+		debugMode          bool                // Whether debug mode is enabled
+		resolvers          []LocaleResolver    // Ordered chain of request language resolvers
+		fallbacks          map[string][]string // Per-language fallback chains
+		messageFormat      MessageFormat       // Message format of language files without "@@format"
+		timeLayouts        map[string]string   // Time layouts for named placeholders keyed by language
+		formatErrorHandler FormatErrorHandler  // Handler notified of format problems
+		watchInterval      time.Duration       // Interval between checks of the language files for changes, 0 disables watching
+		reloadHandler      func(err error)     // Handler notified after every reload
+		refreshInterval    time.Duration       // Interval between unconditional reloads, 0 disables refreshing
+		loadTimeout        time.Duration       // Maximum duration of loading a Loader or calling the override store, 0 for none
+		overrideStore      OverrideStore       // Store persisting runtime overrides, nil for none
+		strict             bool                // Whether loads fail on any problem of the messages, see WithStrict
+		missingHandler     MissingHandler      // Handler notified of missing translations
+		missingStrategy    MissingStrategy     // What missing translations are rendered as
+		logger             *slog.Logger        // Logger of i18n events, nil disables logging
+		logLang            string              // Operator language of log messages
+		usageTracking      bool                // Whether the messages served are recorded, see WithUsageTracking
+		statusCodes        map[int]int         // HTTP status codes keyed by response code
+		statusRanges       []StatusRange       // HTTP status codes of ranges of response codes
+		statusFunc         StatusFunc          // Function mapping response codes to HTTP status codes
+		alwaysOK           bool                // Whether responses are always HTTP 200
 	}
 
-	// Manager handles internationalization operations and language file management
+	// Manager handles internationalization operations and language file management.
+	// It is safe for concurrent use by multiple goroutines.
 	Manager struct {
-		LangList map[string]map[string]string // Map of language codes to their message maps
-		Option   *option                      // Configuration options
-		RunEnv   string                       // Current running environment
+		opt         *option                 // Configuration options, read-only after New
+		runEnv      string                  // Current running environment
+		catalog     atomic.Pointer[catalog] // Active catalog, swapped as a whole on reload
+		defaultLang atomic.Pointer[string]  // Current default language, see SetLang
+		loadMu      sync.Mutex              // Serializes loads so catalogs are swapped in order
+		overrides   Overrides               // Runtime overrides applied over the sources, guarded by loadMu
+		ctx         context.Context         // Canceled by Close to stop watching and refreshing
+		cancel      context.CancelFunc      // Cancels ctx
+		missing     missingRegistry         // Translations found missing
+		metrics     *metrics                // Counters of translations and responses
+		usage       usageTracker            // Messages served, if usage tracking is enabled
 	}
 
 	// result represents the standardized API response structure
@@ -65,12 +99,15 @@ type (
 
 	// Data is a wrapper for response data that includes template parameters
 	Data struct {
-		Params []string    // Parameters for message template
-		Data   interface{} // Actual response data
+		Params []string               // Parameters for message template
+		Args   map[string]interface{} // Named arguments for message template placeholders such as "{name}"
+		Count  interface{}            // Quantity selecting the plural form of the message, nil for none
+		Data   interface{}            // Actual response data
 	}
 )
 
 // WithLangDir returns an Option that sets the language directory path.
+// It is a shorthand for WithSources(DirSource(dir)).
 //
 // Parameters:
 //   - dir: The directory path where language files are stored
@@ -83,7 +120,31 @@ type (
 //	i18n.New(i18n.WithLangDir("./custom/lang/path"))
 func WithLangDir(dir string) Option {
 	return func(o *option) {
-		o.langDir = dir
+		o.sources = []Source{DirSource(dir)}
+	}
+}
+
+// WithFS returns an Option that loads the language files from a file system
+// instead of a directory on disk, such as an embed.FS, so the language files
+// can be compiled into the binary. It is a shorthand for
+// WithSources(FSSource(fsys, root)).
+//
+// Parameters:
+//   - fsys: The file system holding the language files
+//   - root: The directory of the language files within fsys, "." for its root
+//
+// Returns:
+//   - Option: A function that sets the language file system in the options
+//
+// Example:
+//
+//	//go:embed lang
+//	var langFS embed.FS
+//
+//	i18n.New(i18n.WithFS(langFS, "lang"))
+func WithFS(fsys fs.FS, root string) Option {
+	return func(o *option) {
+		o.sources = []Source{FSSource(fsys, root)}
 	}
 }
 
@@ -162,9 +223,14 @@ func WithDebugMode(mode bool) Option {
 func New(opts ...Option) (*Manager, error) {
 	// Initialize options with default values
 	opt := &option{
-		langDir:     defaultLangPath,
-		defaultLang: defaultLang,
-		envKey:      defaultEnvKey,
+		sources:         []Source{DirSource(defaultLangPath)},
+		defaultLang:     defaultLang,
+		envKey:          defaultEnvKey,
+		resolvers:       defaultResolvers(),
+		messageFormat:   PrintfFormat,
+		keySep:          defaultKeySep,
+		missingStrategy: MissingDefaultLang,
+		loadTimeout:     defaultLoadTimeout,
 	}
 
 	// Apply all provided option functions
@@ -172,70 +238,143 @@ func New(opts ...Option) (*Manager, error) {
 		f(opt)
 	}
 
-	// Load language files from the specified directory
-	langList, err := loadLangFiles(opt.langDir)
-	if err != nil {
+	// Get the current running environment from environment variables
+	runEnv := os.Getenv(opt.envKey)
+
+	// Create the Manager instance
+	m := &Manager{opt: opt, runEnv: runEnv, metrics: newMetrics()}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.defaultLang.Store(&opt.defaultLang)
+	m.usage.since = time.Now()
+
+	// Restore the saved runtime overrides
+	if opt.overrideStore != nil {
+		ctx, cancel := m.loadContext()
+		overrides, err := opt.overrideStore.Load(ctx)
+		cancel()
+		if err != nil {
+			m.cancel()
+			return nil, err
+		}
+		m.overrides = overrides
+	}
+
+	// Load the messages of all sources
+	if err := m.load(); err != nil {
+		m.cancel()
 		return nil, err
 	}
 
-	// Ensure at least one language file was loaded
-	if len(langList) == 0 {
-		return nil, errors.New("没有找到语言配置文件")
+	// Watch the language files for changes if enabled
+	if opt.watchInterval > 0 {
+		m.watch()
 	}
 
-	// Get the current running environment from environment variables
-	runEnv := os.Getenv(opt.envKey)
+	// Reload periodically if enabled
+	if opt.refreshInterval > 0 {
+		m.refresh()
+	}
 
-	// Create and return the Manager instance
-	return &Manager{LangList: langList, Option: opt, RunEnv: runEnv}, nil
+	return m, nil
 }
 
-// loadLangFiles reads and parses language files from the specified directory.
-// It walks through the directory, reads each file, and parses its JSON content
-// into a map of message keys to translated messages.
+// loadLangFiles reads and parses language files from a directory of a file system.
+// It walks through the directory, reads each file, and parses its JSON, YAML,
+// TOML or gettext PO/MO content, chosen by the file extension, into a map of message keys
+// to translated messages. Files with other extensions are ignored. Plural
+// objects are flattened into one message per plural category, and nested
+// objects into keys joined with sep (see parseMessages). The files of a
+// language may be split across subdirectories and files (see langPath),
+// and are merged; a key defined by two files is an error.
 //
 // Parameters:
-//   - langDir: The directory path where language files are stored
+//   - fsys: The file system holding the language files
+//   - root: The directory of the language files within fsys
+//   - sep: The separator joining the keys of nested objects
+//   - namespaces: Whether to prefix the keys with the namespace of their file
 //
 // Returns:
 //   - map[string]map[string]string: A map of language codes to their message maps
+//   - map[string]map[string]string: The path of the file defining each message, keyed the same way
 //   - error: An error if reading or parsing files fails, nil otherwise
-func loadLangFiles(langDir string) (map[string]map[string]string, error) {
+func loadLangFiles(fsys fs.FS, root string, sep string, namespaces bool) (map[string]map[string]string, map[string]map[string]string, error) {
 	// Initialize the map to store language configurations
 	langList := make(map[string]map[string]string)
+	// Remember the file defining each key to report duplicates
+	origins := make(map[string]map[string]string)
 
 	// Walk through the language directory
-	err := filepath.Walk(langDir, func(path string, info os.FileInfo, err error) error {
+	err := fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+		// Stop if the directory or a file cannot be read
+		if err != nil {
+			return err
+		}
+
 		// Skip directories, only process files
-		if !info.IsDir() {
-			// Extract language code from filename (without extension)
-			lang := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
-			langConfig := make(map[string]string)
+		if !d.IsDir() {
+			// Skip files that are not language files, such as a README
+			decode, ok := decoderFor(d.Name())
+			if !ok {
+				return nil
+			}
+
+			// Derive the language code and namespace from the path
+			rel := path
+			if root != "." {
+				rel = strings.TrimPrefix(path, root+"/")
+			}
+			lang, namespace, err := langPath(rel, sep)
+			if err != nil {
+				return err
+			}
+			if !namespaces {
+				namespace = ""
+			}
+			raw := make(map[string]interface{})
 
 			// Read file content
 			var byteValue []byte
-			byteValue, err = os.ReadFile(path)
+			byteValue, err = fs.ReadFile(fsys, path)
 			if err != nil {
 				return err
 			}
 
-			// Parse JSON content into the language configuration map
-			if err = json.Unmarshal(byteValue, &langConfig); err != nil {
-				return err
+			// Parse the content into the language configuration map
+			if err = decode(lang, byteValue, &raw); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			// Flatten plural objects into per-category messages
+			var langConfig map[string]string
+			langConfig, err = parseMessages(raw, namespace, sep)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
 			}
 
-			// Store the language configuration in the language list
-			langList[lang] = langConfig
+			// Merge the messages into the language list
+			if langList[lang] == nil {
+				langList[lang] = make(map[string]string, len(langConfig))
+				origins[lang] = make(map[string]string, len(langConfig))
+			}
+			for code, msg := range langConfig {
+				if origin, ok := origins[lang][code]; ok {
+					return fmt.Errorf("%s: key %q: already defined in %s", path, code, origin)
+				}
+				langList[lang][code] = msg
+				origins[lang][code] = path
+			}
 		}
 		return nil
 	})
 
-	return langList, err
+	return langList, origins, err
 }
 
 // lang determines the language to use for the current request.
-// It first checks the "lang" header, then looks for a "lang" parameter
-// in the User-Agent string, and falls back to the default language.
+// It consults the configured resolvers in order and falls back to the
+// default language if none of them reports a language. By default it
+// checks the "lang" header, then the "lang" parameter in the User-Agent
+// string, then negotiates the standard Accept-Language header.
 //
 // Parameters:
 //   - c: The Gin context containing request information
@@ -243,23 +382,16 @@ func loadLangFiles(langDir string) (map[string]map[string]string, error) {
 // Returns:
 //   - string: The language code to use for the current request
 func (m *Manager) lang(c *gin.Context) string {
-	// First priority: Check for "lang" header
-	headerLang := c.Request.Header.Get("lang")
-	if headerLang != "" {
-		return headerLang
-	}
-
-	// Second priority: Check for "lang" parameter in User-Agent
-	ua := c.Request.UserAgent()
-	for _, param := range strings.Split(ua, ";") {
-		paramList := strings.Split(param, "=")
-		if len(paramList) == 2 && paramList[0] == "lang" {
-			return paramList[1]
+	for _, r := range m.opt.resolvers {
+		if lang, ok := r.Resolve(c, m); ok {
+			m.metrics.resolvers.inc(resolverName(r))
+			return lang
 		}
 	}
 
 	// Fallback to default language
-	return m.Option.defaultLang
+	m.metrics.resolvers.inc("default")
+	return m.DefaultLang()
 }
 
 // result creates a standardized response structure for API responses.
@@ -277,6 +409,8 @@ func (m *Manager) lang(c *gin.Context) string {
 func (m *Manager) result(c *gin.Context, code int, data interface{}, err error) result {
 	var res result
 	var tmplPrams []string
+	var tmplArgs map[string]interface{}
+	var count interface{}
 
 	// Set response code
 	res.Code = code
@@ -287,13 +421,16 @@ func (m *Manager) result(c *gin.Context, code int, data interface{}, err error)
 		// If data is a Data struct, extract template parameters and actual data
 		res.Data = d.Data
 		tmplPrams = d.Params
+		tmplArgs = d.Args
+		count = d.Count
 	default:
 		// Otherwise, use data as-is
 		res.Data = data
 	}
 
-	// Translate the message using the determined language and code
-	res.Msg = m.Trans(m.lang(c), strconv.Itoa(code), tmplPrams...)
+	// Translate the message using the determined language and code,
+	// selecting the plural form if a count was provided
+	res.Msg, _ = m.translate(c, m.lang(c), strconv.Itoa(code), count, tmplPrams, tmplArgs)
 
 	// Include trace ID if available in the context
 	traceID, exists := c.Get("trace_id")
@@ -302,14 +439,18 @@ func (m *Manager) result(c *gin.Context, code int, data interface{}, err error)
 	}
 
 	// Include error description in trace if debug mode is enabled
-	if m.isDebugMode(c) && err != nil {
-		res.Trace.Desc = fmt.Sprintf("%v", err)
+	if err != nil {
+		debug, reason := m.debugMode(c)
+		m.log(c, slog.LevelDebug, logDebugDecision, slog.Int("code", code), slog.Bool("enabled", debug), slog.String("reason", reason))
+		if debug {
+			res.Trace.Desc = fmt.Sprintf("%v", err)
+		}
 	}
 
 	return res
 }
 
-// isDebugMode determines whether debug information should be included in responses.
+// debugMode determines whether debug information should be included in responses.
 // The decision is based on a priority hierarchy:
 // 1. Production environment always disables debug mode
 // 2. If not in production, check if debug mode is enabled in options
@@ -320,25 +461,29 @@ func (m *Manager) result(c *gin.Context, code int, data interface{}, err error)
 //
 // Returns:
 //   - bool: true if debug mode is enabled, false otherwise
-func (m *Manager) isDebugMode(c *gin.Context) bool {
+//   - string: The reason of the decision: "prod_env", "option", "header" or "off"
+func (m *Manager) debugMode(c *gin.Context) (bool, string) {
 	// Production environment always disables debug mode
-	if m.RunEnv == "prod" {
-		return false
+	if m.runEnv == "prod" {
+		return false, "prod_env"
 	}
 
 	// If debug mode is enabled in options, enable it
-	if m.Option.debugMode {
-		return true
+	if m.opt.debugMode {
+		return true, "option"
 	}
 
 	// Check for "debug" header in the request
-	debug := c.Request.Header.Get("debug")
+	if c.Request.Header.Get("debug") != "" {
+		return true, "header"
+	}
 
-	return debug != ""
+	return false, "off"
 }
 
 // SetLang changes the default language for the Manager.
 // If an empty string is provided, the default language remains unchanged.
+// It is safe to call while other goroutines are translating.
 //
 // Parameters:
 //   - lang: The new default language code
@@ -348,12 +493,14 @@ func (m *Manager) isDebugMode(c *gin.Context) bool {
 //	manager.SetLang("fr-FR")
 func (m *Manager) SetLang(lang string) {
 	if lang != "" {
-		m.Option.defaultLang = lang
+		m.defaultLang.Store(&lang)
 	}
 }
 
 // Trans translates a message code to a localized message in the specified language.
-// If the language is not supported, it falls back to the default language.
+// If the language is not supported, or the code is missing from it, the
+// languages in its fallback chain are tried in order, ending with the default
+// language (see WithFallbacks).
 // If template parameters are provided, they are formatted into the message.
 //
 // Parameters:
@@ -370,33 +517,190 @@ func (m *Manager) SetLang(lang string) {
 //	message := manager.Trans("en-US", "1001", "World")
 //	// message will be "Hello, World!"
 func (m *Manager) Trans(lang string, code string, params ...string) string {
-	// Get the message map for the specified language
-	l, ok := m.LangList[lang]
-	// If the language is not supported, fall back to the default language
-	if !ok {
-		l = m.LangList[m.Option.defaultLang]
-	}
-
-	// Look up the message for the specified code
-	msg, ok := l[code]
-	if ok {
-		// If template parameters are provided, format them into the message
-		if len(params) > 0 {
-			var ps []interface{}
-			// Convert string parameters to interface{} for fmt.Sprintf
-			for _, p := range params {
-				ps = append(ps, p)
+	msg, _ := m.TransWithLang(lang, code, params...)
+	return msg
+}
+
+// TransWithLang translates a message code like Trans, and also reports
+// which language actually served the message after applying fallbacks.
+//
+// Parameters:
+//   - lang: The language code to use for translation
+//   - code: The message code to translate
+//   - params: Optional parameters to format into the message template
+//
+// Returns:
+//   - string: The translated message, or the original code if no translation is found
+//   - string: The language that served the message, or "" if no translation is found
+//
+// Example:
+//
+//	msg, served := manager.TransWithLang("zh-HK", "1001", "World")
+//	// served will be "zh-CN" if zh-HK falls back to zh-CN
+func (m *Manager) TransWithLang(lang string, code string, params ...string) (string, string) {
+	return m.translate(context.Background(), lang, code, nil, params, nil)
+}
+
+// TransMap translates a message code like Trans, using named arguments
+// instead of positional parameters. Named placeholders such as "{name}"
+// are replaced by the formatted argument values: numbers use the digit
+// grouping and decimal separator of the language, time.Time values use
+// its time layout (see WithTimeLayouts). In ICU MessageFormat patterns
+// (see WithMessageFormat) the arguments are the pattern's arguments.
+//
+// Parameters:
+//   - lang: The language code to use for translation
+//   - code: The message code to translate
+//   - args: Named arguments to format into the message template
+//
+// Returns:
+//   - string: The translated message, or the original code if no translation is found
+//
+// Example:
+//
+//	// Assuming "1002" maps to "Hello, {name}! Your balance is {balance}"
+//	message := manager.TransMap("en-US", "1002", map[string]interface{}{"name": "Seakee", "balance": 1234.5})
+//	// message will be "Hello, Seakee! Your balance is 1,234.5"
+func (m *Manager) TransMap(lang string, code string, args map[string]interface{}) string {
+	msg, _ := m.translate(context.Background(), lang, code, nil, nil, args)
+	return msg
+}
+
+// translate looks up a message along the fallback chain of lang and
+// renders it with the given parameters and arguments. A printf message
+// whose verbs don't match the number of parameters is reported to the
+// format error handler and skipped in favor of the next language of the
+// chain; if no language can render it, the first message found is returned
+// unformatted, so "%!s(MISSING)" or "%!(EXTRA ...)" never reach users.
+// Serving the default language for a language whose chain has loaded
+// languages means the translation is missing, which is reported and
+// rendered as set by WithMissingStrategy. Languages that are not loaded
+// are served the default language without reporting.
+//
+// Parameters:
+//   - ctx: The context of the translation, such as the gin context of a response
+//   - lang: The language code to use for translation
+//   - code: The message code to translate
+//   - count: The quantity selecting the plural form, nil for none
+//   - params: Positional template parameters
+//   - args: Named template arguments
+//
+// Returns:
+//   - string: The translated message, or the placeholder of a missing translation
+//   - string: The language that served the message, or "" if no translation is found
+func (m *Manager) translate(ctx context.Context, lang string, code string, count interface{}, params []string, args map[string]interface{}) (string, string) {
+	// Translate with a single snapshot, whatever reloads happen meanwhile
+	cat := m.catalog.Load()
+	def := m.DefaultLang()
+	chain := m.fallbackChain(lang)
+
+	// Only languages with loaded messages can miss translations
+	loaded := false
+	for _, l := range chain {
+		if _, ok := cat.langs[l]; ok && l != def {
+			loaded = true
+			break
+		}
+	}
+
+	missing := false
+	var unformatted, unformattedLang string
+	for _, l := range chain {
+		// Look up the message for the specified code
+		msg, ok := m.lookup(cat, l, code, count)
+		if !ok {
+			continue
+		}
+
+		// The default language is the last resort of missing translations
+		if l == def && l != lang && loaded && unformattedLang == "" {
+			if m.opt.missingStrategy != MissingDefaultLang {
+				continue
 			}
+			missing = true
+			m.reportMissing(ctx, lang, code)
+		}
 
-			// Format the message with the parameters
-			msg = fmt.Sprintf(msg, ps...)
+		// Plural forms may omit the count, so surplus parameters are dropped
+		n, ferr := m.checkArgs(cat, l, code, msg, len(params), count != nil)
+		if ferr != nil {
+			m.reportFormatError(ctx, ferr)
+			if unformattedLang == "" {
+				unformatted, unformattedLang = msg, l
+			}
+			continue
 		}
 
+		m.recordServed(lang, l, code)
+		return m.render(cat, l, msg, params[:n], args), l
+	}
+
+	if unformattedLang != "" {
+		m.recordServed(lang, unformattedLang, code)
+		return m.replaceNamed(unformattedLang, unformatted, args, false), unformattedLang
+	}
+
+	// If no translation is found, return the placeholder. Codes missing
+	// for languages that are not loaded are reported for the default one.
+	if !missing {
+		if !loaded {
+			lang = def
+		}
+		m.reportMissing(ctx, lang, code)
+	}
+
+	return m.missingText(code), ""
+}
+
+// lookup looks up a message in a single language, selecting its plural
+// form if a count is given. Without a count, a plural message is looked up
+// by its "other" form.
+//
+// Parameters:
+//   - cat: The catalog to look up
+//   - lang: The language code to look up
+//   - code: The message code
+//   - count: The quantity selecting the plural form, nil for none
+//
+// Returns:
+//   - string: The message template
+//   - bool: true if the language has the message, false otherwise
+func (m *Manager) lookup(cat *catalog, lang string, code string, count interface{}) (string, bool) {
+	if count != nil {
+		return m.lookupPlural(cat, lang, code, count)
+	}
+
+	messages := cat.langs[lang]
+	if msg, ok := messages[code]; ok {
+		return msg, true
+	}
+
+	msg, ok := messages[code+pluralSep+pluralForms[plural.Other]]
+	return msg, ok
+}
+
+// format formats template parameters into a message.
+// The message is returned unchanged if no parameters are provided.
+//
+// Parameters:
+//   - msg: The message template
+//   - params: The parameters to format into the template
+//
+// Returns:
+//   - string: The formatted message
+func format(msg string, params []string) string {
+	if len(params) == 0 {
 		return msg
 	}
 
-	// If no translation is found, return the original code
-	return code
+	var ps []interface{}
+	// Convert string parameters to interface{} for fmt.Sprintf
+	for _, p := range params {
+		ps = append(ps, p)
+	}
+
+	// Format the message with the parameters
+	return fmt.Sprintf(msg, ps...)
 }
 
 // Count returns the number of supported languages.
@@ -409,7 +713,7 @@ func (m *Manager) Trans(lang string, code string, params ...string) string {
 //	count := manager.Count()
 //	fmt.Printf("Supported languages: %d\n", count)
 func (m *Manager) Count() int {
-	return len(m.LangList)
+	return len(m.langList())
 }
 
 // Lang returns a list of all supported language codes.
@@ -424,7 +728,7 @@ func (m *Manager) Count() int {
 func (m *Manager) Lang() []string {
 	var list []string
 	// Iterate through all language codes in the language list
-	for lang := range m.LangList {
+	for lang := range m.langList() {
 		if lang != "" {
 			list = append(list, lang)
 		}
@@ -447,7 +751,7 @@ func (m *Manager) Lang() []string {
 //	    fmt.Println("French is supported")
 //	}
 func (m *Manager) LangExist(lang string) bool {
-	_, ok := m.LangList[lang]
+	_, ok := m.langList()[lang]
 	return ok
 }
 
@@ -456,7 +760,7 @@ func (m *Manager) LangExist(lang string) bool {
 //
 // Parameters:
 //   - c: The Gin context for the HTTP response
-//   - code: The response code (used for message lookup)
+//   - code: The response code (used for message lookup and the HTTP status, see HTTPStatus)
 //   - data: The response data or Data struct with template parameters
 //   - err: An error to include in trace information (if debug mode is enabled)
 //
@@ -467,10 +771,11 @@ func (m *Manager) LangExist(lang string) bool {
 //	    manager.JSON(c, 200, data, nil)
 //	}
 func (m *Manager) JSON(c *gin.Context, code int, data interface{}, err error) {
+	m.metrics.responses.inc("json")
 	// Store the response code in the context for potential middleware use
 	c.Set("response_code", code)
 	// Send JSON response with standardized structure
-	c.JSON(http.StatusOK, m.result(c, code, data, err))
+	c.JSON(m.HTTPStatus(code), m.result(c, code, data, err))
 }
 
 // JSONP serializes the given struct as JSON into the response body with JSONP support.
@@ -478,7 +783,7 @@ func (m *Manager) JSON(c *gin.Context, code int, data interface{}, err error) {
 //
 // Parameters:
 //   - c: The Gin context for the HTTP response
-//   - code: The response code (used for message lookup)
+//   - code: The response code (used for message lookup and the HTTP status, see HTTPStatus)
 //   - data: The response data or Data struct with template parameters
 //   - err: An error to include in trace information (if debug mode is enabled)
 //
@@ -490,7 +795,8 @@ func (m *Manager) JSON(c *gin.Context, code int, data interface{}, err error) {
 //	    // Response: callback({"code": 200, "msg": "...", "data": ...})
 //	}
 func (m *Manager) JSONP(c *gin.Context, code int, data interface{}, err error) {
-	c.JSONP(http.StatusOK, m.result(c, code, data, err))
+	m.metrics.responses.inc("jsonp")
+	c.JSONP(m.HTTPStatus(code), m.result(c, code, data, err))
 }
 
 // AsciiJSON serializes the given struct as JSON into the response body,
@@ -498,7 +804,7 @@ func (m *Manager) JSONP(c *gin.Context, code int, data interface{}, err error) {
 //
 // Parameters:
 //   - c: The Gin context for the HTTP response
-//   - code: The response code (used for message lookup)
+//   - code: The response code (used for message lookup and the HTTP status, see HTTPStatus)
 //   - data: The response data or Data struct with template parameters
 //   - err: An error to include in trace information (if debug mode is enabled)
 //
@@ -510,7 +816,8 @@ func (m *Manager) JSONP(c *gin.Context, code int, data interface{}, err error) {
 //	    // Response: {"name":"\u4e16\u754c"}
 //	}
 func (m *Manager) AsciiJSON(c *gin.Context, code int, data interface{}, err error) {
-	c.AsciiJSON(http.StatusOK, m.result(c, code, data, err))
+	m.metrics.responses.inc("ascii_json")
+	c.AsciiJSON(m.HTTPStatus(code), m.result(c, code, data, err))
 }
 
 // PureJSON serializes the given struct as JSON into the response body,
@@ -518,7 +825,7 @@ func (m *Manager) AsciiJSON(c *gin.Context, code int, data interface{}, err erro
 //
 // Parameters:
 //   - c: The Gin context for the HTTP response
-//   - code: The response code (used for message lookup)
+//   - code: The response code (used for message lookup and the HTTP status, see HTTPStatus)
 //   - data: The response data or Data struct with template parameters
 //   - err: An error to include in trace information (if debug mode is enabled)
 //
@@ -530,7 +837,8 @@ func (m *Manager) AsciiJSON(c *gin.Context, code int, data interface{}, err erro
 //	    // Response: {"html":"<p>Hello</p>"}
 //	}
 func (m *Manager) PureJSON(c *gin.Context, code int, data interface{}, err error) {
-	c.PureJSON(http.StatusOK, m.result(c, code, data, err))
+	m.metrics.responses.inc("pure_json")
+	c.PureJSON(m.HTTPStatus(code), m.result(c, code, data, err))
 }
 
 // XML serializes the given struct as XML into the response body.
@@ -538,7 +846,7 @@ func (m *Manager) PureJSON(c *gin.Context, code int, data interface{}, err error
 //
 // Parameters:
 //   - c: The Gin context for the HTTP response
-//   - code: The response code (used for message lookup)
+//   - code: The response code (used for message lookup and the HTTP status, see HTTPStatus)
 //   - data: The response data or Data struct with template parameters
 //   - err: An error to include in trace information (if debug mode is enabled)
 //
@@ -559,7 +867,8 @@ func (m *Manager) PureJSON(c *gin.Context, code int, data interface{}, err error
 //	    //          </response>
 //	}
 func (m *Manager) XML(c *gin.Context, code int, data interface{}, err error) {
-	c.XML(http.StatusOK, m.result(c, code, data, err))
+	m.metrics.responses.inc("xml")
+	c.XML(m.HTTPStatus(code), m.result(c, code, data, err))
 }
 
 // YAML serializes the given struct as YAML into the response body.
@@ -567,7 +876,7 @@ func (m *Manager) XML(c *gin.Context, code int, data interface{}, err error) {
 //
 // Parameters:
 //   - c: The Gin context for the HTTP response
-//   - code: The response code (used for message lookup)
+//   - code: The response code (used for message lookup and the HTTP status, see HTTPStatus)
 //   - data: The response data or Data struct with template parameters
 //   - err: An error to include in trace information (if debug mode is enabled)
 //
@@ -583,5 +892,6 @@ func (m *Manager) XML(c *gin.Context, code int, data interface{}, err error) {
 //	    //   name: test
 //	}
 func (m *Manager) YAML(c *gin.Context, code int, data interface{}, err error) {
-	c.YAML(http.StatusOK, m.result(c, code, data, err))
+	m.metrics.responses.inc("yaml")
+	c.YAML(m.HTTPStatus(code), m.result(c, code, data, err))
 }
@@ -9,6 +9,8 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -66,7 +68,11 @@ func TestI18n(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		assert.Equal(t, 200, w.Code)
+		status := http.StatusOK
+		if api.Code == 500 || api.Code == 400 {
+			status = api.Code
+		}
+		assert.Equal(t, status, w.Code)
 		assert.Equal(t, api, res)
 	}
 }
@@ -107,3 +113,35 @@ func setupRouter() *gin.Engine {
 
 	return r
 }
+
+// newTestManager writes the given language files into a temporary directory
+// and returns a Manager loading them.
+func newTestManager(t *testing.T, files map[string]string, opts ...Option) *Manager {
+	t.Helper()
+
+	m, err := New(append([]Option{WithLangDir(writeLangDir(t, files))}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+// writeLangDir writes the given language files, keyed by their path
+// relative to the directory, into a temporary directory and returns it.
+func writeLangDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
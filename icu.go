@@ -0,0 +1,573 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"fmt"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PrintfFormat treats messages as fmt.Sprintf templates with positional verbs such as %s
+	PrintfFormat MessageFormat = "printf"
+	// ICUFormat treats messages as ICU MessageFormat patterns such as
+	// "{name} has {count, plural, one {# file} other {# files}}"
+	ICUFormat MessageFormat = "icu"
+
+	// formatKey is the metadata key that selects the message format of a
+	// single language file, e.g. "@@format": "icu"
+	formatKey = "@@format"
+)
+
+type (
+	// MessageFormat selects how message templates are parsed and rendered
+	MessageFormat string
+
+	// icuMessage is a parsed ICU MessageFormat pattern
+	icuMessage []icuPart
+
+	// icuPart is a literal text, an argument or a "#" placeholder of an ICU pattern
+	icuPart struct {
+		kind    icuKind               // Kind of the part
+		text    string                // Literal text, for icuText
+		arg     string                // Argument name, for arguments
+		typ     string                // Argument type, e.g. "number", for icuSimple
+		offset  float64               // Plural offset, for icuPlural
+		options map[string]icuMessage // Sub-messages keyed by selector, for icuPlural and icuSelect
+	}
+
+	// icuKind identifies the kind of an icuPart
+	icuKind int
+
+	// icuRenderer renders parsed ICU patterns in one language
+	icuRenderer struct {
+		m    *Manager               // Manager formatting the argument values
+		lang string                 // Language code of the message
+		args map[string]interface{} // Named arguments
+		b    strings.Builder        // Rendered output
+	}
+
+	// icuParser parses an ICU MessageFormat pattern
+	icuParser struct {
+		src string // Pattern being parsed
+		pos int    // Current byte offset in src
+	}
+)
+
+const (
+	icuText icuKind = iota
+	icuSimple
+	icuPlural
+	icuOrdinal
+	icuSelect
+	icuPound
+)
+
+// WithMessageFormat returns an Option that sets the message format used
+// for all language files. A language file can override it with the
+// "@@format" metadata key, e.g. {"@@format": "icu"}.
+//
+// Parameters:
+//   - format: PrintfFormat (default) or ICUFormat
+//
+// Returns:
+//   - Option: A function that sets the message format in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithMessageFormat(i18n.ICUFormat))
+func WithMessageFormat(format MessageFormat) Option {
+	return func(o *option) {
+		o.messageFormat = format
+	}
+}
+
+// isMetaKey reports whether a catalog key holds file metadata, such as
+// "@@format", rather than a message.
+//
+// Parameters:
+//   - key: The catalog key
+//
+// Returns:
+//   - bool: true if the key is a metadata key, false otherwise
+func isMetaKey(key string) bool {
+	return strings.HasPrefix(key, "@")
+}
+
+// formatOf returns the message format of a language's messages.
+//
+// Parameters:
+//   - messages: The messages of the language
+//
+// Returns:
+//   - MessageFormat: The format declared by the "@@format" key, or the global format
+func (m *Manager) formatOf(messages map[string]string) MessageFormat {
+	if f, ok := messages[formatKey]; ok {
+		return MessageFormat(f)
+	}
+
+	return m.opt.messageFormat
+}
+
+// compileMessages checks the declared message formats and parses every
+// ICU message of a language list, so syntax errors are reported before the
+// list is used and rendering never parses a pattern twice.
+//
+// Parameters:
+//   - langList: A map of language codes to their message maps
+//   - prev: Patterns already parsed, reused for unchanged messages, may be nil
+//
+// Returns:
+//   - map[string]icuMessage: The parsed patterns keyed by message, to be kept in the catalog
+//   - error: An error describing the first invalid format or pattern, nil otherwise
+func (m *Manager) compileMessages(langList map[string]map[string]string, prev map[string]icuMessage) (map[string]icuMessage, error) {
+	compiled := make(map[string]icuMessage)
+	for lang, messages := range langList {
+		switch f := m.formatOf(messages); f {
+		case PrintfFormat:
+			continue
+		case ICUFormat:
+		default:
+			return nil, fmt.Errorf("%s: unknown message format %q", lang, f)
+		}
+
+		for code, msg := range messages {
+			if isMetaKey(code) {
+				continue
+			}
+			if _, ok := compiled[msg]; ok {
+				continue
+			}
+			if parsed, ok := prev[msg]; ok {
+				compiled[msg] = parsed
+				continue
+			}
+			parsed, err := parseICU(msg)
+			if err != nil {
+				return nil, fmt.Errorf("%s: key %q: %w", lang, code, err)
+			}
+			compiled[msg] = parsed
+		}
+	}
+
+	return compiled, nil
+}
+
+// icu returns the parsed ICU pattern of a message. Patterns of a catalog
+// are parsed when it is loaded; other messages are parsed on each call, so
+// nothing outlives the catalog.
+//
+// Parameters:
+//   - cat: The catalog of the message, nil for none
+//   - msg: The ICU MessageFormat pattern
+//
+// Returns:
+//   - icuMessage: The parsed pattern
+//   - error: A syntax error, nil otherwise
+func icu(cat *catalog, msg string) (icuMessage, error) {
+	if cat != nil {
+		if parsed, ok := cat.icu[msg]; ok {
+			return parsed, nil
+		}
+	}
+
+	return parseICU(msg)
+}
+
+// parseICU parses an ICU MessageFormat pattern.
+//
+// Parameters:
+//   - msg: The ICU MessageFormat pattern
+//
+// Returns:
+//   - icuMessage: The parsed pattern
+//   - error: A syntax error, nil otherwise
+func parseICU(msg string) (icuMessage, error) {
+	p := &icuParser{src: msg}
+	parsed, err := p.parse(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+
+	return parsed, nil
+}
+
+// render formats the arguments into a message of the given language,
+// according to the language's message format. Named arguments replace
+// "{name}" placeholders, and positional parameters are formatted with
+// fmt.Sprintf; in ICU patterns they are the numbered arguments {0}, {1}, ...
+//
+// Parameters:
+//   - cat: The catalog of the message
+//   - lang: The language code of the message
+//   - msg: The message template
+//   - params: Positional template parameters
+//   - args: Named template arguments
+//
+// Returns:
+//   - string: The rendered message
+func (m *Manager) render(cat *catalog, lang string, msg string, params []string, args map[string]interface{}) string {
+	if m.formatOf(cat.langs[lang]) != ICUFormat {
+		// The template is formatted by fmt.Sprintf only with parameters
+		return format(m.replaceNamed(lang, msg, args, len(params) > 0), params)
+	}
+
+	parsed, err := icu(cat, msg)
+	if err != nil {
+		return msg
+	}
+
+	if len(params) > 0 {
+		merged := make(map[string]interface{}, len(args)+len(params))
+		for i, p := range params {
+			merged[strconv.Itoa(i)] = p
+		}
+		for k, v := range args {
+			merged[k] = v
+		}
+		args = merged
+	}
+
+	r := &icuRenderer{m: m, lang: lang, args: args}
+	r.render(parsed, nil)
+	return r.b.String()
+}
+
+// render writes the pattern to the renderer's output.
+// number is the value "#" stands for inside a plural sub-message.
+func (r *icuRenderer) render(msg icuMessage, number interface{}) {
+	for _, part := range msg {
+		switch part.kind {
+		case icuText:
+			r.b.WriteString(part.text)
+		case icuPound:
+			if number != nil {
+				r.b.WriteString(r.m.formatValue(r.lang, number))
+			} else {
+				r.b.WriteByte('#')
+			}
+		case icuSimple:
+			v, ok := r.args[part.arg]
+			if !ok {
+				// Keep the placeholder visible rather than rendering an empty value
+				r.b.WriteString("{" + part.arg + "}")
+				continue
+			}
+			r.b.WriteString(r.m.formatValue(r.lang, v))
+		case icuSelect:
+			v, ok := r.args[part.arg]
+			sub, found := part.options[fmt.Sprint(v)]
+			if !ok || !found {
+				sub = part.options["other"]
+			}
+			r.render(sub, number)
+		case icuPlural, icuOrdinal:
+			r.renderPlural(part)
+		}
+	}
+}
+
+// renderPlural selects and writes the sub-message of a plural or
+// selectordinal argument: an exact "=n" selector wins over the plural
+// category of the value minus the offset, and "other" is the last resort.
+func (r *icuRenderer) renderPlural(part icuPart) {
+	v := r.args[part.arg]
+	n, ok := toFloat(v)
+	if !ok {
+		r.render(part.options["other"], v)
+		return
+	}
+
+	if sub, found := part.options["="+strconv.FormatFloat(n, 'f', -1, 64)]; found {
+		r.render(sub, v)
+		return
+	}
+
+	// The category and "#" use the value minus the offset; without an
+	// offset the original value is kept so visible fraction digits count
+	value := v
+	if part.offset != 0 {
+		value = n - part.offset
+	}
+
+	category := pluralForms[plural.Other]
+	if part.kind == icuOrdinal {
+		if tag, err := language.Parse(r.lang); err == nil {
+			i, v, w, f, t, _ := pluralOperands(value)
+			category = pluralForms[plural.Ordinal.MatchPlural(tag, i, v, w, f, t)]
+		}
+	} else {
+		category = pluralCategory(r.lang, value)
+	}
+
+	sub, found := part.options[category]
+	if !found {
+		sub = part.options["other"]
+	}
+	r.render(sub, value)
+}
+
+// toFloat converts a numeric argument to float64.
+//
+// Parameters:
+//   - v: An integer, a floating-point number or a decimal string
+//
+// Returns:
+//   - float64: The numeric value
+//   - bool: false if v is not a number
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// parse parses a message up to the end of the pattern or, for a
+// sub-message, up to its closing brace. inPlural enables the "#" placeholder.
+func (p *icuParser) parse(inPlural bool) (icuMessage, error) {
+	var msg icuMessage
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() > 0 {
+			msg = append(msg, icuPart{kind: icuText, text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '}':
+			flush()
+			return msg, nil
+		case c == '{':
+			flush()
+			part, err := p.parseArgument()
+			if err != nil {
+				return nil, err
+			}
+			msg = append(msg, part)
+		case c == '#' && inPlural:
+			flush()
+			msg = append(msg, icuPart{kind: icuPound})
+			p.pos++
+		case c == '\'':
+			p.parseQuoted(&text, inPlural)
+		default:
+			text.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	flush()
+	return msg, nil
+}
+
+// parseQuoted handles an apostrophe: a doubled apostrophe is literal, and an
+// apostrophe before a special character quotes the text up to the next
+// single apostrophe. Any other apostrophe is literal.
+func (p *icuParser) parseQuoted(text *strings.Builder, inPlural bool) {
+	p.pos++
+	if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+		text.WriteByte('\'')
+		p.pos++
+		return
+	}
+
+	if p.pos >= len(p.src) || !(p.src[p.pos] == '{' || p.src[p.pos] == '}' || p.src[p.pos] == '|' || (inPlural && p.src[p.pos] == '#')) {
+		text.WriteByte('\'')
+		return
+	}
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+		if c != '\'' {
+			text.WriteByte(c)
+			continue
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+			text.WriteByte('\'')
+			p.pos++
+			continue
+		}
+		return
+	}
+}
+
+// parseArgument parses an argument starting at an opening brace, e.g.
+// "{name}", "{count, number}" or "{count, plural, one {...} other {...}}".
+func (p *icuParser) parseArgument() (icuPart, error) {
+	start := p.pos
+	p.pos++ // Skip "{"
+
+	name := p.parseIdentifier()
+	if name == "" {
+		return icuPart{}, p.errorf("missing argument name")
+	}
+
+	p.skipSpace()
+	if p.consume('}') {
+		return icuPart{kind: icuSimple, arg: name}, nil
+	}
+	if !p.consume(',') {
+		return icuPart{}, p.errorf("expected \",\" or \"}\" after argument %q", name)
+	}
+
+	typ := p.parseIdentifier()
+	switch typ {
+	case "plural", "selectordinal", "select":
+	case "":
+		return icuPart{}, p.errorf("missing type of argument %q", name)
+	default:
+		// Simple argument such as "{d, date, short}", the style is not interpreted
+		depth := 0
+		for ; p.pos < len(p.src); p.pos++ {
+			switch p.src[p.pos] {
+			case '{':
+				depth++
+			case '}':
+				if depth == 0 {
+					p.pos++
+					return icuPart{kind: icuSimple, arg: name, typ: typ}, nil
+				}
+				depth--
+			}
+		}
+		return icuPart{}, p.errorAt(start, "unterminated argument %q", name)
+	}
+
+	p.skipSpace()
+	if !p.consume(',') {
+		return icuPart{}, p.errorf("expected \",\" after %s type of argument %q", typ, name)
+	}
+
+	part := icuPart{arg: name, options: make(map[string]icuMessage)}
+	switch typ {
+	case "plural":
+		part.kind = icuPlural
+	case "selectordinal":
+		part.kind = icuOrdinal
+	default:
+		part.kind = icuSelect
+	}
+
+	for {
+		p.skipSpace()
+		if p.consume('}') {
+			break
+		}
+		if p.pos >= len(p.src) {
+			return icuPart{}, p.errorAt(start, "unterminated argument %q", name)
+		}
+
+		selector := p.parseIdentifier()
+		if selector == "" {
+			return icuPart{}, p.errorf("missing selector in argument %q", name)
+		}
+
+		// "offset:n" may precede the selectors of a plural argument
+		if strings.HasPrefix(selector, "offset:") && part.kind != icuSelect && len(part.options) == 0 {
+			offset, err := strconv.ParseFloat(strings.TrimPrefix(selector, "offset:"), 64)
+			if err != nil {
+				return icuPart{}, p.errorf("invalid plural offset %q", selector)
+			}
+			part.offset = offset
+			continue
+		}
+
+		p.skipSpace()
+		if !p.consume('{') {
+			return icuPart{}, p.errorf("expected \"{\" after selector %q", selector)
+		}
+		sub, err := p.parse(part.kind != icuSelect)
+		if err != nil {
+			return icuPart{}, err
+		}
+		if !p.consume('}') {
+			return icuPart{}, p.errorf("unterminated sub-message %q of argument %q", selector, name)
+		}
+		part.options[selector] = sub
+	}
+
+	if _, ok := part.options["other"]; !ok {
+		return icuPart{}, p.errorAt(start, "argument %q has no \"other\" sub-message", name)
+	}
+
+	return part, nil
+}
+
+// parseIdentifier skips leading white space and returns the following
+// run of characters up to white space or a syntax character.
+func (p *icuParser) parseIdentifier() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(" \t\r\n{},#'", rune(p.src[p.pos])) {
+		p.pos++
+	}
+
+	return p.src[start:p.pos]
+}
+
+// skipSpace skips white space.
+func (p *icuParser) skipSpace() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// consume skips c if it is the next character and reports whether it did.
+func (p *icuParser) consume(c byte) bool {
+	if p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+// errorf returns a syntax error at the current position.
+func (p *icuParser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.pos, format, args...)
+}
+
+// errorAt returns a syntax error at the given position.
+func (p *icuParser) errorAt(pos int, format string, args ...interface{}) error {
+	return fmt.Errorf("icu: %s at offset %d in %q", fmt.Sprintf(format, args...), pos, p.src)
+}
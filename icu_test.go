@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestICUMessages(t *testing.T) {
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{
+			"1": "{name} has {count, plural, =0 {no files} one {# file} other {# files}}",
+			"2": "{gender, select, male {He} female {She} other {They}} replied",
+			"3": "Hello, {0}! Your account is {1}",
+			"4": "{count, plural, offset:1 =0 {nobody} =1 {{name}} one {{name} and # other} other {{name} and # others}}",
+			"5": "It''s '{literal}' {place, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}",
+			"6": "{missing} stays"
+		}`,
+		"ru-RU.json": `{"1": "{count, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}"}`,
+		"zh-CN.json": `{"@@format": "printf", "1": "%s个文件"}`,
+	}, WithMessageFormat(ICUFormat))
+
+	args := func(kv ...interface{}) map[string]interface{} {
+		a := make(map[string]interface{})
+		for i := 0; i < len(kv); i += 2 {
+			a[kv[i].(string)] = kv[i+1]
+		}
+		return a
+	}
+
+	assert.Equal(t, "Seakee has no files", m.TransMap("en-US", "1", args("name", "Seakee", "count", 0)))
+	assert.Equal(t, "Seakee has 1 file", m.TransMap("en-US", "1", args("name", "Seakee", "count", 1)))
+	assert.Equal(t, "Seakee has 3 files", m.TransMap("en-US", "1", args("name", "Seakee", "count", 3)))
+	assert.Equal(t, "She replied", m.TransMap("en-US", "2", args("gender", "female")))
+	assert.Equal(t, "They replied", m.TransMap("en-US", "2", nil))
+	assert.Equal(t, "Hello, Seakee! Your account is 188", m.Trans("en-US", "3", "Seakee", "188"))
+	assert.Equal(t, "Ann", m.TransMap("en-US", "4", args("name", "Ann", "count", 1)))
+	assert.Equal(t, "Ann and 1 other", m.TransMap("en-US", "4", args("name", "Ann", "count", 2)))
+	assert.Equal(t, "Ann and 4 others", m.TransMap("en-US", "4", args("name", "Ann", "count", 5)))
+	assert.Equal(t, "It's {literal} 22nd", m.TransMap("en-US", "5", args("place", 22)))
+	assert.Equal(t, "{missing} stays", m.TransMap("en-US", "6", nil))
+	assert.Equal(t, "5 файлов", m.TransMap("ru-RU", "1", args("count", 5)))
+	assert.Equal(t, "3个文件", m.Trans("zh-CN", "1", "3"))
+}
+
+func TestICUSyntaxErrors(t *testing.T) {
+	for _, pattern := range []string{
+		"{name",
+		"{count, plural, one {# file}}",
+		"{count, plural, one {# file} other {# files}",
+		"{}",
+		"text }",
+		"{gender, select other {x}}",
+	} {
+		_, err := parseICU(pattern)
+		assert.Error(t, err, pattern)
+	}
+
+	dir := writeLangDir(t, map[string]string{"en-US.json": `{"1": "{count, plural, one {x}}"}`})
+	_, err := New(WithLangDir(dir), WithMessageFormat(ICUFormat))
+	assert.Error(t, err)
+
+	dir = writeLangDir(t, map[string]string{"en-US.json": `{"@@format": "markdown", "1": "x"}`})
+	_, err = New(WithLangDir(dir))
+	assert.Error(t, err)
+}
+
+func TestICUPatternsReplacedWithCatalog(t *testing.T) {
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{"1": "{count, plural, one {# file} other {# files}}"}`,
+	}, WithMessageFormat(ICUFormat))
+
+	for i := 0; i < 3; i++ {
+		msg := fmt.Sprintf("{count, plural, one {# file %d} other {# files %d}}", i, i)
+		assert.NoError(t, m.Set("en-US", "1", msg))
+		assert.Equal(t, fmt.Sprintf("2 files %d", i), m.TransMap("en-US", "1", map[string]interface{}{"count": 2}))
+	}
+
+	// Only the patterns of the active catalog are kept
+	patterns := m.catalog.Load().icu
+	assert.Len(t, patterns, 1)
+	assert.Contains(t, patterns, "{count, plural, one {# file 2} other {# files 2}}")
+}
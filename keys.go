@@ -0,0 +1,158 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultKeySep joins the keys of nested objects in language files
+const defaultKeySep = "."
+
+// WithKeySeparator returns an Option that sets the separator joining the
+// keys of nested objects in language files into message codes. With the
+// default ".", {"user": {"login": {"failed": "..."}}} is translated with
+// the code "user.login.failed". An empty separator keeps the default.
+//
+// Parameters:
+//   - sep: The separator
+//
+// Returns:
+//   - Option: A function that sets the key separator in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithKeySeparator("_"))
+func WithKeySeparator(sep string) Option {
+	return func(o *option) {
+		if sep != "" {
+			o.keySep = sep
+		}
+	}
+}
+
+// parseMessages converts the decoded content of a language file into a
+// flat map of message codes to messages. String values are used as-is;
+// an object of plural categories is stored as one message per category,
+// keyed by the code and the category joined with pluralSep. Any other
+// object is a namespace whose keys are joined to its own with sep.
+//
+// Parameters:
+//   - raw: The decoded language file content
+//   - prefix: The namespace of the file, prefixed to every code, "" for none
+//   - sep: The separator joining nested keys
+//
+// Returns:
+//   - map[string]string: The messages keyed by code
+//   - error: An error if a value is neither a string nor an object, or two
+//     keys flatten to the same code
+func parseMessages(raw map[string]interface{}, prefix string, sep string) (map[string]string, error) {
+	messages := make(map[string]string, len(raw))
+	if err := flattenMessages(messages, prefix, raw, sep); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// flattenMessages adds the messages of a decoded object to messages,
+// prefixing their codes with prefix.
+//
+// Parameters:
+//   - messages: The flat messages to add to
+//   - prefix: The code of the object, "" for the top level
+//   - raw: The decoded object
+//   - sep: The separator joining nested keys
+//
+// Returns:
+//   - error: An error if a value is neither a string nor an object, or two
+//     keys flatten to the same code
+func flattenMessages(messages map[string]string, prefix string, raw map[string]interface{}, sep string) error {
+	add := func(code string, msg string) error {
+		if _, ok := messages[code]; ok {
+			return fmt.Errorf("key %q: duplicate message", code)
+		}
+		messages[code] = msg
+		return nil
+	}
+
+	for key, value := range raw {
+		code := key
+		if prefix != "" {
+			code = prefix + sep + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			if err := add(code, v); err != nil {
+				return err
+			}
+		case map[string]interface{}:
+			if !isPluralObject(v) {
+				if err := flattenMessages(messages, code, v, sep); err != nil {
+					return err
+				}
+				continue
+			}
+			for category, form := range v {
+				if err := add(code+pluralSep+category, form.(string)); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("key %q: message must be a string, an object of plural forms or an object of nested messages", code)
+		}
+	}
+
+	return nil
+}
+
+// isPluralObject reports whether a decoded object holds the plural forms of
+// a message: it is not empty and all its values are strings keyed by a
+// plural category.
+//
+// Parameters:
+//   - v: The decoded object
+//
+// Returns:
+//   - bool: true if v is a plural object, false if it is a namespace
+func isPluralObject(v map[string]interface{}) bool {
+	for category, form := range v {
+		if _, ok := form.(string); !ok || !isPluralCategory(category) {
+			return false
+		}
+	}
+
+	return len(v) > 0
+}
+
+// locateKey finds where a flat message code belongs in a decoded language
+// file, descending into the nested objects that its leading keys name, so
+// updates land next to the existing messages of the same namespace.
+//
+// Parameters:
+//   - raw: The decoded language file content
+//   - code: The message code
+//   - sep: The separator joining nested keys
+//
+// Returns:
+//   - map[string]interface{}: The object that holds the code
+//   - string: The key of the code within that object
+func locateKey(raw map[string]interface{}, code string, sep string) (map[string]interface{}, string) {
+	for i := strings.Index(code, sep); i > 0; {
+		if sub, ok := raw[code[:i]].(map[string]interface{}); ok && !isPluralObject(sub) {
+			return locateKey(sub, code[i+len(sep):], sep)
+		}
+
+		next := strings.Index(code[i+len(sep):], sep)
+		if next < 0 {
+			break
+		}
+		i += len(sep) + next
+	}
+
+	return raw, code
+}
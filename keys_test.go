@@ -0,0 +1,114 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNestedKeys(t *testing.T) {
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{
+			"0": "ok",
+			"user": {
+				"login": {"failed": "Login failed", "locked": "Account %s is locked"},
+				"inbox": {"one": "%s message", "other": "%s messages"}
+			}
+		}`,
+		"zh-CN.yaml": "user:\n  login:\n    failed: 登录失败\n",
+		"ja-JP.toml": "[user.login]\nfailed = \"ログイン失敗\"\n",
+	})
+
+	assert.Equal(t, "Login failed", m.Trans("en-US", "user.login.failed"))
+	assert.Equal(t, "Account 188 is locked", m.Trans("en-US", "user.login.locked", "188"))
+	assert.Equal(t, "3 messages", m.TransPlural("en-US", "user.inbox", 3, "3"))
+	assert.Equal(t, "登录失败", m.Trans("zh-CN", "user.login.failed"))
+	assert.Equal(t, "ログイン失敗", m.Trans("ja-JP", "user.login.failed"))
+	assert.Equal(t, "Account 188 is locked", m.Trans("zh-CN", "user.login.locked", "188"))
+}
+
+func TestWithKeySeparator(t *testing.T) {
+	files := map[string]string{
+		"en-US.json": `{"user": {"login": {"failed": "Login failed"}, "inbox": {"one": "%s message", "other": "%s messages"}}}`,
+	}
+
+	m := newTestManager(t, files, WithKeySeparator("_"))
+	assert.Equal(t, "Login failed", m.Trans("en-US", "user_login_failed"))
+	assert.Equal(t, "user.login.failed", m.Trans("en-US", "user.login.failed"))
+	assert.Equal(t, "1 message", m.TransPlural("en-US", "user_inbox", 1, "1"))
+
+	m = newTestManager(t, files, WithKeySeparator(""))
+	assert.Equal(t, "Login failed", m.Trans("en-US", "user.login.failed"))
+
+	// Gettext contexts are namespaces too
+	m = newTestManager(t, map[string]string{
+		"en-US.po": "msgctxt \"admin\"\nmsgid \"0\"\nmsgstr \"done\"\n",
+	}, WithKeySeparator("::"))
+	assert.Equal(t, "done", m.Trans("en-US", "admin::0"))
+}
+
+func TestNestedKeysDuplicate(t *testing.T) {
+	_, err := New(WithLangDir(writeLangDir(t, map[string]string{
+		"en-US.json": `{"user.login": "flat", "user": {"login": "nested"}}`,
+	})))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `"user.login"`)
+	}
+
+	var v map[string]interface{}
+	err = decodePO("en", []byte("msgctxt \"admin\"\nmsgid \"0\"\nmsgstr \"done\"\n\nmsgid \"admin\"\nmsgstr \"Admin\"\n"), &v)
+	assert.Error(t, err)
+}
+
+func TestLocateKey(t *testing.T) {
+	raw := map[string]interface{}{
+		"user": map[string]interface{}{
+			"login": map[string]interface{}{"failed": "x"},
+			"inbox": map[string]interface{}{"one": "x", "other": "y"},
+		},
+		"a.b": map[string]interface{}{"c": "x"},
+	}
+
+	obj, key := locateKey(raw, "user.login.failed", ".")
+	assert.Equal(t, "failed", key)
+	assert.Equal(t, raw["user"].(map[string]interface{})["login"], obj)
+
+	// Plural objects are not namespaces
+	obj, key = locateKey(raw, "user.inbox.few", ".")
+	assert.Equal(t, "inbox.few", key)
+	assert.Equal(t, raw["user"], obj)
+
+	obj, key = locateKey(raw, "a.b.c", ".")
+	assert.Equal(t, "c", key)
+	assert.Equal(t, raw["a.b"], obj)
+
+	obj, key = locateKey(raw, "other.key", ".")
+	assert.Equal(t, "other.key", key)
+	assert.Equal(t, raw, obj)
+}
+
+func TestImportXLIFFNested(t *testing.T) {
+	dir := writeLangDir(t, map[string]string{
+		"en-US.json": `{"user": {"login": {"failed": "Login failed"}}}`,
+		"de.json":    `{"user": {"login": {"failed": "Fehler"}}}`,
+	})
+	m, err := New(WithLangDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.ImportXLIFF(strings.NewReader(`<xliff version="1.2"><file target-language="de"><body>
+		<trans-unit id="user.login.failed"><source>Login failed</source><target>Anmeldung fehlgeschlagen</target></trans-unit>
+	</body></file></xliff>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Anmeldung fehlgeschlagen", m.Trans("de", "user.login.failed"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "de.json"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n  \"user\": {\n    \"login\": {\n      \"failed\": \"Anmeldung fehlgeschlagen\"\n    }\n  }\n}\n", string(data))
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"fmt"
+	"golang.org/x/text/language"
+	"path"
+	"strings"
+)
+
+// WithNamespaces returns an Option that prefixes the message codes of
+// language files in subdirectories with a namespace derived from their
+// path. The namespace is made of the directories and the file name other
+// than the language, joined with the key separator: the messages of
+// lang/order/en-US.json and of lang/en-US/order.json are both translated
+// with codes such as "order.1000".
+//
+// Parameters:
+//   - enabled: Whether to prefix message codes with namespaces
+//
+// Returns:
+//   - Option: A function that sets the namespace mode in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithNamespaces(true))
+func WithNamespaces(enabled bool) Option {
+	return func(o *option) {
+		o.namespaces = enabled
+	}
+}
+
+// langPath derives the language and the namespace of a language file from
+// its path relative to the language directory. Two layouts are detected: a
+// file in a top-level directory named with a language tag belongs to that
+// language, such as "en-US/order.json"; otherwise the language is the file
+// name without extension, such as "order/en-US.json". Module names such as
+// "api" or "pay" are language tags too, so when both the top-level
+// directory and the file name are, the one with a script or region subtag
+// is the language, such as in "pay/en-US.json", and the path is rejected
+// if that does not tell them apart. The namespace is made of the other
+// directories and the file name if it is not the language, joined with sep.
+//
+// Parameters:
+//   - rel: The slash-separated path of the file relative to the language directory
+//   - sep: The separator joining the parts of the namespace
+//
+// Returns:
+//   - string: The language of the file
+//   - string: The namespace of the file, "" for none
+//   - error: An error if the language of the file is ambiguous
+func langPath(rel string, sep string) (string, string, error) {
+	dirs := strings.Split(path.Dir(rel), "/")
+	if dirs[0] == "." {
+		dirs = nil
+	}
+	name := path.Base(rel)
+	stem := strings.TrimSuffix(name, path.Ext(name))
+
+	if len(dirs) == 0 || !isLangTag(dirs[0]) {
+		return stem, strings.Join(dirs, sep), nil
+	}
+
+	if stem != dirs[0] && isLangTag(stem) {
+		switch dirQualified, stemQualified := isQualifiedTag(dirs[0]), isQualifiedTag(stem); {
+		case stemQualified && !dirQualified:
+			return stem, strings.Join(dirs, sep), nil
+		case stemQualified == dirQualified:
+			return "", "", fmt.Errorf("%s: ambiguous language, %q and %q are both language codes", rel, dirs[0], stem)
+		}
+	}
+
+	return dirs[0], strings.Join(append(append([]string{}, dirs[1:]...), stem), sep), nil
+}
+
+// isQualifiedTag reports whether the language tag s has a script or a
+// region subtag, such as "zh-Hans" or "en-US".
+//
+// Parameters:
+//   - s: The language tag
+//
+// Returns:
+//   - bool: true if s has a script or a region, false otherwise
+func isQualifiedTag(s string) bool {
+	_, script, region := language.Make(s).Raw()
+	return script != language.Script{} || region != language.Region{}
+}
+
+// isLangTag reports whether s is a well-formed and known BCP 47 language tag.
+//
+// Parameters:
+//   - s: The string to check
+//
+// Returns:
+//   - bool: true if s is a language tag, false otherwise
+func isLangTag(s string) bool {
+	_, err := language.Parse(s)
+	return err == nil
+}
@@ -0,0 +1,131 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestLangPath(t *testing.T) {
+	tests := []struct {
+		rel       string
+		lang      string
+		namespace string
+	}{
+		{"en-US.json", "en-US", ""},
+		{"custom.json", "custom", ""},
+		{"order/en-US.json", "en-US", "order"},
+		{"shop/order/zh-CN.yaml", "zh-CN", "shop.order"},
+		{"en-US/order.json", "en-US", "order"},
+		{"en-US/shop/order.json", "en-US", "shop.order"},
+		{"misc/custom.json", "custom", "misc"},
+		// Module files named like language tags stay in their language directory
+		{"en-US/api.json", "en-US", "api"},
+		{"zh-CN/app/log.yaml", "zh-CN", "app.log"},
+		// and module directories named like language tags keep their files
+		{"pay/en-US.json", "en-US", "pay"},
+		{"api/zh-Hans.yaml", "zh-Hans", "api"},
+		{"app/v2/zh-CN.json", "zh-CN", "app.v2"},
+	}
+
+	for _, tt := range tests {
+		lang, namespace, err := langPath(tt.rel, ".")
+		assert.NoError(t, err, tt.rel)
+		assert.Equal(t, tt.lang, lang, tt.rel)
+		assert.Equal(t, tt.namespace, namespace, tt.rel)
+	}
+
+	_, namespace, _ := langPath("en-US/shop/order.json", "::")
+	assert.Equal(t, "shop::order", namespace)
+
+	// Paths that do not tell the language apart are rejected
+	for _, rel := range []string{"pay/en.json", "en-US/zh-CN.json"} {
+		_, _, err := langPath(rel, ".")
+		assert.ErrorContains(t, err, rel)
+	}
+}
+
+func TestSplitCatalogs(t *testing.T) {
+	files := map[string]string{
+		"user/en-US.json":  `{"1000": "Hello"}`,
+		"order/en-US.json": `{"2000": "Order placed"}`,
+		"zh-CN/user.json":  `{"1000": "你好"}`,
+		"zh-CN/order.yaml": "2000: 已下单\n",
+		"zh-CN/api.json":   `{"3000": "接口错误"}`,
+		"pay/en-US.json":   `{"4000": "Paid"}`,
+		"README.md":        "# Catalogs",
+	}
+
+	m := newTestManager(t, files)
+	assert.ElementsMatch(t, []string{"en-US", "zh-CN"}, m.Lang())
+	assert.Equal(t, "Hello", m.Trans("en-US", "1000"))
+	assert.Equal(t, "Order placed", m.Trans("en-US", "2000"))
+	assert.Equal(t, "已下单", m.Trans("zh-CN", "2000"))
+	assert.Equal(t, "接口错误", m.Trans("zh-CN", "3000"))
+	assert.Equal(t, "Paid", m.Trans("en-US", "4000"))
+
+	m = newTestManager(t, files, WithNamespaces(true))
+	assert.Equal(t, "Hello", m.Trans("en-US", "user.1000"))
+	assert.Equal(t, "Order placed", m.Trans("en-US", "order.2000"))
+	assert.Equal(t, "你好", m.Trans("zh-CN", "user.1000"))
+	assert.Equal(t, "已下单", m.Trans("zh-CN", "order.2000"))
+	assert.Equal(t, "接口错误", m.Trans("zh-CN", "api.3000"))
+	assert.Equal(t, "Paid", m.Trans("en-US", "pay.4000"))
+	assert.Equal(t, "1000", m.Trans("en-US", "1000"))
+
+	m = newTestManager(t, files, WithNamespaces(true), WithKeySeparator("/"))
+	assert.Equal(t, "你好", m.Trans("zh-CN", "user/1000"))
+}
+
+func TestSplitCatalogsDuplicate(t *testing.T) {
+	_, err := New(WithLangDir(writeLangDir(t, map[string]string{
+		"user/en-US.json":  `{"1000": "Hello"}`,
+		"order/en-US.json": `{"1000": "Order placed"}`,
+	})))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `key "1000": already defined in order/en-US.json`)
+	}
+
+	// Namespaces keep the keys apart
+	_, err = New(WithLangDir(writeLangDir(t, map[string]string{
+		"user/en-US.json":  `{"1000": "Hello"}`,
+		"order/en-US.json": `{"1000": "Order placed"}`,
+	})), WithNamespaces(true))
+	assert.NoError(t, err)
+}
+
+func TestSplitCatalogsAmbiguous(t *testing.T) {
+	_, err := New(WithLangDir(writeLangDir(t, map[string]string{
+		"en-US.json":  `{"0": "ok"}`,
+		"pay/en.json": `{"4000": "Paid"}`,
+	})))
+	assert.ErrorContains(t, err, `pay/en.json: ambiguous language, "pay" and "en" are both language codes`)
+}
+
+func TestImportXLIFFLayouts(t *testing.T) {
+	doc := func(lang string, id string) string {
+		return `<xliff version="1.2"><file target-language="` + lang + `"><body>
+			<trans-unit id="` + id + `"><source>x</source><target>translated</target></trans-unit>
+		</body></file></xliff>`
+	}
+
+	m := newTestManager(t, map[string]string{
+		"en-US.json":       `{"0": "ok"}`,
+		"user/de.json":     `{"1000": "Hallo"}`,
+		"order/fr-FR.json": `{"2000": "Commandé"}`,
+		"order/ja.json":    `{"2000": "注文"}`,
+		"user/ja.json":     `{"1000": "こんにちは"}`,
+	}, WithNamespaces(true))
+
+	// Codes of a namespaced file are written without the namespace
+	if assert.NoError(t, m.ImportXLIFF(strings.NewReader(doc("de", "user.1001")))) {
+		assert.Equal(t, "translated", m.Trans("de", "user.1001"))
+	}
+	assert.Error(t, m.ImportXLIFF(strings.NewReader(doc("fr-FR", "user.1001"))))
+
+	// Split languages cannot be written
+	err := m.ImportXLIFF(strings.NewReader(doc("ja", "user.1001")))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "split across")
+	}
+}
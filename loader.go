@@ -0,0 +1,252 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	// defaultSQLQuery is the query used by SQLLoader when none is given
+	defaultSQLQuery = "SELECT lang, code, message FROM i18n_messages"
+	// defaultLoadTimeout bounds each load of a Loader and each call of the
+	// override store when no timeout is set
+	defaultLoadTimeout = 30 * time.Second
+)
+
+type (
+	// Loader loads messages from an external system, such as a database or
+	// a translation service. Its messages replace the language files when
+	// set with WithLoader, or form a layer with LoaderSource.
+	Loader interface {
+		// Load returns the messages keyed by language code and then by
+		// message code. Plural forms are keyed like "1001.one". ctx
+		// expires after the load timeout (see WithLoadTimeout).
+		Load(ctx context.Context) (map[string]map[string]string, error)
+	}
+
+	// LoaderFunc adapts an ordinary function to the Loader interface.
+	LoaderFunc func(ctx context.Context) (map[string]map[string]string, error)
+
+	// separatedLoader is implemented by Loaders of nested messages, which
+	// join nested keys with the key separator of the Manager loading them
+	separatedLoader interface {
+		loadSep(ctx context.Context, sep string) (map[string]map[string]string, error)
+	}
+
+	// loaderSource is a Source loading its messages with a Loader
+	loaderSource struct {
+		name   string // Source name
+		loader Loader // Loader of the messages
+	}
+
+	// sqlLoader loads messages from a database
+	sqlLoader struct {
+		db    *sql.DB // Database holding the messages
+		query string  // Query returning (lang, code, message) rows
+	}
+)
+
+// WithLoader returns an Option that loads the messages with a Loader
+// instead of language files. It is a shorthand for
+// WithSources(LoaderSource("loader", l)).
+//
+// Parameters:
+//   - l: The loader of the messages
+//
+// Returns:
+//   - Option: A function that sets the loader as the only source in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithLoader(i18n.SQLLoader(db, "")), i18n.WithRefresh(time.Minute))
+func WithLoader(l Loader) Option {
+	return func(o *option) {
+		o.sources = []Source{LoaderSource("loader", l)}
+	}
+}
+
+// WithRefresh returns an Option that reloads all sources at the given
+// interval, so messages edited in a database or a translation service are
+// picked up without restarting. Failed refreshes keep the previous
+// messages and are reported to the reload handler (see WithReloadHandler).
+// Call Close to stop refreshing.
+//
+// Parameters:
+//   - interval: The interval between reloads, 0 disables refreshing
+//
+// Returns:
+//   - Option: A function that sets the refresh interval in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithLoader(loader), i18n.WithRefresh(5*time.Minute))
+func WithRefresh(interval time.Duration) Option {
+	return func(o *option) {
+		o.refreshInterval = interval
+	}
+}
+
+// WithLoadTimeout returns an Option that bounds each load of a Loader and
+// each call of the override store, so an unreachable database or service
+// fails the load instead of blocking it. The default is 30 seconds.
+//
+// Parameters:
+//   - timeout: The maximum duration of a load, 0 or less for no timeout
+//
+// Returns:
+//   - Option: A function that sets the load timeout in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithLoader(loader), i18n.WithLoadTimeout(5*time.Second))
+func WithLoadTimeout(timeout time.Duration) Option {
+	return func(o *option) {
+		o.loadTimeout = timeout
+	}
+}
+
+// Load calls f(ctx).
+func (f LoaderFunc) Load(ctx context.Context) (map[string]map[string]string, error) {
+	return f(ctx)
+}
+
+// LoaderSource returns a Source loading its messages with a Loader, so
+// external messages can be layered over or under language files.
+//
+// Parameters:
+//   - name: The name of the source
+//   - l: The loader of the messages
+//
+// Returns:
+//   - Source: The loader source
+//
+// Example:
+//
+//	i18n.New(i18n.WithSources(
+//	    i18n.DirSource("./lang"),
+//	    i18n.LoaderSource("db", i18n.SQLLoader(db, "")),
+//	))
+func LoaderSource(name string, l Loader) Source {
+	return &loaderSource{name: name, loader: l}
+}
+
+// Name implements Source.
+func (s *loaderSource) Name() string {
+	return s.name
+}
+
+// Load implements Source.
+func (s *loaderSource) Load(m *Manager) (map[string]map[string]string, error) {
+	ctx, cancel := m.loadContext()
+	defer cancel()
+
+	return loadWithSep(ctx, s.loader, m.opt.keySep)
+}
+
+// loadWithSep loads the messages of a Loader, joining nested keys with sep
+// if it loads nested messages.
+//
+// Parameters:
+//   - ctx: The context of the load
+//   - l: The loader of the messages
+//   - sep: The separator joining nested keys
+//
+// Returns:
+//   - map[string]map[string]string: The messages keyed by language and code
+//   - error: An error if the messages cannot be loaded
+func loadWithSep(ctx context.Context, l Loader, sep string) (map[string]map[string]string, error) {
+	if sl, ok := l.(separatedLoader); ok {
+		return sl.loadSep(ctx, sep)
+	}
+
+	return l.Load(ctx)
+}
+
+// SQLLoader returns a Loader reading messages from a database. The query
+// must return (lang, code, message) rows; rows with a NULL message are
+// skipped. Any database/sql driver can be used.
+//
+// Parameters:
+//   - db: The database holding the messages
+//   - query: The query returning the messages, "" for
+//     "SELECT lang, code, message FROM i18n_messages"
+//
+// Returns:
+//   - Loader: The database loader
+//
+// Example:
+//
+//	loader := i18n.SQLLoader(db, "SELECT locale, msg_key, text FROM translations WHERE published")
+func SQLLoader(db *sql.DB, query string) Loader {
+	if query == "" {
+		query = defaultSQLQuery
+	}
+
+	return &sqlLoader{db: db, query: query}
+}
+
+// Load implements Loader.
+func (l *sqlLoader) Load(ctx context.Context) (map[string]map[string]string, error) {
+	rows, err := l.db.QueryContext(ctx, l.query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	langList := make(map[string]map[string]string)
+	for rows.Next() {
+		var lang, code string
+		var msg sql.NullString
+		if err = rows.Scan(&lang, &code, &msg); err != nil {
+			return nil, err
+		}
+		if !msg.Valid {
+			continue
+		}
+
+		if langList[lang] == nil {
+			langList[lang] = make(map[string]string)
+		}
+		langList[lang][code] = msg.String
+	}
+
+	return langList, rows.Err()
+}
+
+// loadContext returns the context of a load, expiring after the load
+// timeout, if any. It is independent of Close, which only stops background
+// work.
+//
+// Returns:
+//   - context.Context: The context of the load
+//   - context.CancelFunc: The function releasing the context
+func (m *Manager) loadContext() (context.Context, context.CancelFunc) {
+	if m.opt.loadTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithTimeout(context.Background(), m.opt.loadTimeout)
+}
+
+// refresh starts reloading all sources periodically in the background.
+func (m *Manager) refresh() {
+	go func() {
+		ticker := time.NewTicker(m.opt.refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+				// Failures are reported to the reload handler
+				_ = m.Reload()
+			}
+		}
+	}()
+}
@@ -0,0 +1,208 @@
+package i18n
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDriver is a database/sql driver serving the rows of an in-memory
+// table to any query.
+type fakeDriver struct {
+	mu      sync.Mutex
+	rows    [][]driver.Value
+	err     error
+	queries []string
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	i    int
+}
+
+var fakeSQL = &fakeDriver{}
+
+func init() {
+	sql.Register("i18nfake", fakeSQL)
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+func (d *fakeDriver) set(rows [][]driver.Value, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rows, d.err, d.queries = rows, err, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.d, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return 0 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.queries = append(s.d.queries, s.query)
+	if s.d.err != nil {
+		return nil, s.d.err
+	}
+	return &fakeRows{rows: append([][]driver.Value(nil), s.d.rows...)}, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"lang", "code", "message"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.i])
+	r.i++
+	return nil
+}
+
+func openFakeDB(t *testing.T, rows [][]driver.Value, err error) *sql.DB {
+	t.Helper()
+
+	fakeSQL.set(rows, err)
+	db, openErr := sql.Open("i18nfake", "")
+	if openErr != nil {
+		t.Fatal(openErr)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func TestSQLLoader(t *testing.T) {
+	db := openFakeDB(t, [][]driver.Value{
+		{"en-US", "0", "ok"},
+		{"en-US", "1001.one", "%s item"},
+		{"en-US", "1001.other", "%s items"},
+		{"zh-CN", "0", "成功"},
+		{"zh-CN", "500", nil},
+	}, nil)
+
+	m, err := New(WithLoader(SQLLoader(db, "")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	assert.Equal(t, []string{defaultSQLQuery}, fakeSQL.queries)
+	assert.ElementsMatch(t, []string{"en-US", "zh-CN"}, m.Lang())
+	assert.Equal(t, "成功", m.Trans("zh-CN", "0"))
+	assert.Equal(t, "3 items", m.TransPlural("en-US", "1001", 3, "3"))
+	assert.Equal(t, "500", m.Trans("zh-CN", "500"))
+	origin, _ := m.Origin("zh-CN", "0")
+	assert.Equal(t, "loader", origin)
+
+	_, err = SQLLoader(db, "SELECT locale, k, v FROM t").Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT locale, k, v FROM t", fakeSQL.queries[len(fakeSQL.queries)-1])
+
+	openFakeDB(t, nil, errors.New("connection refused"))
+	_, err = New(WithLoader(SQLLoader(db, "")))
+	assert.EqualError(t, err, "loader: connection refused")
+}
+
+func TestWithRefresh(t *testing.T) {
+	db := openFakeDB(t, [][]driver.Value{{"en-US", "0", "ok"}}, nil)
+
+	var mu sync.Mutex
+	var reloadErr error
+	m, err := New(
+		WithSources(DirSource("./lang"), LoaderSource("db", SQLLoader(db, ""))),
+		WithRefresh(10*time.Millisecond),
+		WithReloadHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reloadErr = err
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	assert.Equal(t, "ok", m.Trans("en-US", "0"))
+	assert.Equal(t, "fail", m.Trans("en-US", "500"))
+
+	fakeSQL.set([][]driver.Value{{"en-US", "0", "all good"}}, nil)
+	assert.Eventually(t, func() bool { return m.Trans("en-US", "0") == "all good" }, time.Second, 5*time.Millisecond)
+
+	// A failed refresh keeps the previous messages
+	fakeSQL.set(nil, errors.New("connection refused"))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return reloadErr != nil
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "all good", m.Trans("en-US", "0"))
+
+	// Closing stops refreshing
+	m.Close()
+	fakeSQL.set([][]driver.Value{{"en-US", "0", "closed"}}, nil)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, "all good", m.Trans("en-US", "0"))
+}
+
+func TestLoaderFunc(t *testing.T) {
+	var got context.Context
+	loads := 0
+	m, err := New(WithLoadTimeout(time.Minute), WithLoader(LoaderFunc(func(ctx context.Context) (map[string]map[string]string, error) {
+		got = ctx
+		loads++
+		return map[string]map[string]string{"en-US": {"0": "ok"}}, nil
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "ok", m.Trans("en-US", "0"))
+
+	// Loads are bounded by the load timeout
+	deadline, ok := got.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 10*time.Second)
+
+	// Closing stops background work only
+	m.Close()
+	assert.NoError(t, m.Reload())
+	assert.Equal(t, 2, loads)
+}
+
+func TestWithLoadTimeout(t *testing.T) {
+	_, err := New(WithLoadTimeout(10*time.Millisecond), WithLoader(LoaderFunc(func(ctx context.Context) (map[string]map[string]string, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// 0 disables the timeout
+	var got context.Context
+	_, err = New(WithLoadTimeout(0), WithLoader(LoaderFunc(func(ctx context.Context) (map[string]map[string]string, error) {
+		got = ctx
+		return map[string]map[string]string{"en-US": {"0": "ok"}}, ctx.Err()
+	})))
+	if assert.NoError(t, err) {
+		_, ok := got.Deadline()
+		assert.False(t, ok)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logKeyPrefix prefixes the message codes of log messages in the catalog,
+// e.g. "i18n.log.missing_translation"
+const logKeyPrefix = "i18n.log."
+
+// Log events, see logMessages
+const (
+	logReloaded      = "reloaded"
+	logReloadFailed  = "reload_failed"
+	logMissing       = "missing_translation"
+	logFormatError   = "format_error"
+	logDebugDecision = "debug_mode"
+)
+
+// logMessages are the default messages of the log events
+var logMessages = map[string]string{
+	logReloaded:      "i18n: messages reloaded",
+	logReloadFailed:  "i18n: reload rejected, previous messages kept",
+	logMissing:       "i18n: missing translation",
+	logFormatError:   "i18n: message format mismatch",
+	logDebugDecision: "i18n: debug information decided",
+}
+
+// WithLogger returns an Option that sets the logger of i18n events:
+//   - reloads (Info) and rejected reloads (Error), with the error;
+//   - missing translations (Warn), the first time each is missing, with lang and key;
+//   - format mismatches of printf messages (Warn), with lang, key and error;
+//   - whether debug information is included in a response with an error
+//     (Debug), with code, the decision and its reason.
+//
+// Events of responses carry the "trace_id" of the gin context, and the
+// context is passed to the handler. Logging is disabled by default.
+//
+// Parameters:
+//   - l: The logger, nil to disable logging
+//
+// Returns:
+//   - Option: A function that sets the logger in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithLogger(slog.Default()))
+func WithLogger(l *slog.Logger) Option {
+	return func(o *option) {
+		o.logger = l
+	}
+}
+
+// WithLogLang returns an Option that sets the operator language of log
+// messages, independent of the languages of users. Log messages are read
+// from the codes "i18n.log.<event>" of that language, e.g.
+// "i18n.log.missing_translation", falling back to English, and events
+// about a message code carry its message in that language as "message".
+//
+// Parameters:
+//   - lang: The operator language code
+//
+// Returns:
+//   - Option: A function that sets the log language in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithLogger(slog.Default()), i18n.WithLogLang("zh-CN"))
+func WithLogLang(lang string) Option {
+	return func(o *option) {
+		o.logLang = lang
+	}
+}
+
+// log emits an event to the logger, if any.
+//
+// Parameters:
+//   - ctx: The context of the event, such as a gin context carrying a "trace_id"
+//   - level: The level of the event
+//   - event: The event, one of the log* constants
+//   - attrs: The attributes of the event
+func (m *Manager) log(ctx context.Context, level slog.Level, event string, attrs ...slog.Attr) {
+	if m.opt.logger == nil || !m.opt.logger.Enabled(ctx, level) {
+		return
+	}
+
+	if traceID, ok := ctx.Value("trace_id").(string); ok && traceID != "" {
+		attrs = append(attrs, slog.String("trace_id", traceID))
+	}
+
+	msg := logMessages[event]
+	if localized, ok := m.langList()[m.opt.logLang][logKeyPrefix+event]; ok {
+		msg = localized
+	}
+
+	m.opt.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// logKey returns the attributes of an event about a message code: the
+// language, the code and, with an operator language, its message there.
+//
+// Parameters:
+//   - lang: The language of the event
+//   - key: The message code
+//
+// Returns:
+//   - []slog.Attr: The attributes
+func (m *Manager) logKey(lang string, key string) []slog.Attr {
+	attrs := []slog.Attr{slog.String("lang", lang), slog.String("key", key)}
+	if m.opt.logLang == "" {
+		return attrs
+	}
+	if msg, ok := m.lookup(m.catalog.Load(), m.opt.logLang, key, nil); ok {
+		attrs = append(attrs, slog.String("message", msg))
+	}
+
+	return attrs
+}
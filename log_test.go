@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// logRecords decodes the records written by a slog JSON handler.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatal(err)
+		}
+		delete(rec, "time")
+		records = append(records, rec)
+	}
+	buf.Reset()
+
+	return records
+}
+
+func TestWithLogger(t *testing.T) {
+	dir := writeLangDir(t, map[string]string{
+		"en-US.json": `{"0": "ok", "500": "fail", "1000": "Hello,%s!"}`,
+		"zh-CN.json": `{"0": "成功", "1000": "你好!"}`,
+	})
+	var buf bytes.Buffer
+	m, err := New(
+		WithLangDir(dir),
+		WithDefaultLang("en-US"),
+		WithLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []map[string]interface{}{{
+		"level": "WARN",
+		"msg":   "i18n: message format mismatch",
+		"lang":  "zh-CN",
+		"key":   "1000",
+		"error": `i18n: zh-CN: key "1000": message takes 0 arguments but en-US takes 1`,
+	}}, logRecords(t, &buf))
+
+	// Events of responses carry the trace ID
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		c.Set("trace_id", "abc")
+		m.JSON(c, 404, nil, errors.New("not found"))
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("lang", "zh-CN")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, []map[string]interface{}{
+		{"level": "WARN", "msg": "i18n: missing translation", "lang": "zh-CN", "key": "404", "trace_id": "abc"},
+		{"level": "DEBUG", "msg": "i18n: debug information decided", "code": 404.0, "enabled": false, "reason": "off", "trace_id": "abc"},
+	}, logRecords(t, &buf))
+
+	// Missing translations are logged once
+	m.Trans("zh-CN", "404")
+	assert.Empty(t, logRecords(t, &buf))
+
+	assert.NoError(t, m.Reload())
+	assert.Contains(t, logRecords(t, &buf), map[string]interface{}{"level": "INFO", "msg": "i18n: messages reloaded", "langs": 2.0})
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "zh-CN.json"), []byte(`{`), 0o644))
+	assert.Error(t, m.Reload())
+	records := logRecords(t, &buf)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "ERROR", records[0]["level"])
+		assert.Equal(t, "i18n: reload rejected, previous messages kept", records[0]["msg"])
+		assert.Contains(t, records[0]["error"], "zh-CN.json")
+	}
+}
+
+func TestWithLogLang(t *testing.T) {
+	var buf bytes.Buffer
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{"0": "ok", "500": "fail"}`,
+		"zh-CN.json": `{"500": "失败", "i18n": {"log": {"missing_translation": "翻译缺失"}}}`,
+		"de.json":    `{"0": "gut"}`,
+	}, WithDefaultLang("en-US"), WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))), WithLogLang("zh-CN"))
+
+	m.Trans("de", "500")
+	m.Trans("zh-CN", "0")
+	assert.Equal(t, []map[string]interface{}{
+		{"level": "WARN", "msg": "翻译缺失", "lang": "de", "key": "500", "message": "失败"},
+		{"level": "WARN", "msg": "翻译缺失", "lang": "zh-CN", "key": "0"},
+	}, logRecords(t, &buf))
+}
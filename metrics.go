@@ -0,0 +1,240 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// maxSeries bounds the label values counted per metric, as languages
+	// may come from request headers
+	maxSeries = 256
+	// otherSeries is the label value counting the values beyond maxSeries
+	otherSeries = "other"
+	// labelSep joins the label values of a series
+	labelSep = "\x00"
+)
+
+var (
+	// labelEscaper escapes label values in the text exposition format
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	// expvarMu serializes checking and publishing expvar variables, as
+	// expvar.Publish panics on names already published
+	expvarMu sync.Mutex
+)
+
+type (
+	// namedResolver is implemented by resolvers reporting a name to the
+	// metrics, see MetricsHandler.
+	namedResolver interface {
+		Name() string
+	}
+
+	// counterVec is a set of counters keyed by label values
+	counterVec struct {
+		name   string       // Metric name
+		help   string       // Metric description
+		labels []string     // Label names
+		series sync.Map     // Counters keyed by label values joined with labelSep
+		size   atomic.Int32 // Number of series
+	}
+
+	// metrics counts the translations and responses of a Manager
+	metrics struct {
+		translations *counterVec // Translations served, by serving language
+		fallbacks    *counterVec // Translations served by a fallback, by requested and serving language
+		missing      *counterVec // Missing translations, by requested language
+		resolvers    *counterVec // Request languages determined, by resolver
+		responses    *counterVec // Responses written, by format
+	}
+)
+
+// newMetrics returns zeroed metrics.
+//
+// Returns:
+//   - *metrics: The metrics
+func newMetrics() *metrics {
+	return &metrics{
+		translations: &counterVec{name: "i18n_translations_total", help: "Translations served, by serving language.", labels: []string{"lang"}},
+		fallbacks:    &counterVec{name: "i18n_fallbacks_total", help: "Translations served by a fallback language.", labels: []string{"lang", "served"}},
+		missing:      &counterVec{name: "i18n_missing_total", help: "Missing translations, by requested language.", labels: []string{"lang"}},
+		resolvers:    &counterVec{name: "i18n_resolver_total", help: "Request languages determined, by resolver.", labels: []string{"resolver"}},
+		responses:    &counterVec{name: "i18n_responses_total", help: "Responses written, by format.", labels: []string{"format"}},
+	}
+}
+
+// all returns the counters in exposition order.
+//
+// Returns:
+//   - []*counterVec: The counters
+func (ms *metrics) all() []*counterVec {
+	return []*counterVec{ms.translations, ms.fallbacks, ms.missing, ms.resolvers, ms.responses}
+}
+
+// inc increments the counter of the given label values. Beyond maxSeries
+// series, new label values are counted as "other".
+//
+// Parameters:
+//   - values: The label values, one per label name
+func (v *counterVec) inc(values ...string) {
+	key := strings.Join(values, labelSep)
+	if c, ok := v.series.Load(key); ok {
+		c.(*atomic.Int64).Add(1)
+		return
+	}
+
+	if v.size.Load() >= maxSeries {
+		others := make([]string, len(values))
+		for i := range others {
+			others[i] = otherSeries
+		}
+		key = strings.Join(others, labelSep)
+	}
+	c, loaded := v.series.LoadOrStore(key, new(atomic.Int64))
+	if !loaded {
+		v.size.Add(1)
+	}
+	c.(*atomic.Int64).Add(1)
+}
+
+// snapshot returns the current values of the counters.
+//
+// Returns:
+//   - map[string]int64: The values keyed by label values joined with labelSep
+func (v *counterVec) snapshot() map[string]int64 {
+	values := make(map[string]int64)
+	v.series.Range(func(key, c interface{}) bool {
+		values[key.(string)] = c.(*atomic.Int64).Load()
+		return true
+	})
+
+	return values
+}
+
+// recordServed counts a translation served, and whether it was served by
+// a fallback language, and records the usage of the message.
+//
+// Parameters:
+//   - lang: The requested language
+//   - served: The language that served the translation
+//   - code: The message code
+func (m *Manager) recordServed(lang string, served string, code string) {
+	m.recordUsage(served, code)
+	m.metrics.translations.inc(served)
+	if served != lang {
+		m.metrics.fallbacks.inc(lang, served)
+	}
+}
+
+// resolverName returns the name a resolver is counted as.
+//
+// Parameters:
+//   - r: The resolver
+//
+// Returns:
+//   - string: The name of the resolver, or "custom" if it has none
+func resolverName(r LocaleResolver) string {
+	if n, ok := r.(namedResolver); ok {
+		return n.Name()
+	}
+
+	return "custom"
+}
+
+// MetricsHandler returns an http.Handler exposing the metrics of the
+// Manager in the Prometheus text exposition format, so they can be scraped
+// without depending on the Prometheus client:
+//   - i18n_translations_total{lang}: translations served, by serving language
+//   - i18n_fallbacks_total{lang,served}: translations served by a fallback language
+//   - i18n_missing_total{lang}: missing translations, by requested language
+//   - i18n_resolver_total{resolver}: request languages determined, by resolver
+//     ("header", "user_agent", "accept_language", ..., or "default")
+//   - i18n_responses_total{format}: responses written, by format ("json", "xml", ...)
+//
+// At most 256 label values are counted per metric, further ones are
+// counted as "other".
+//
+// Returns:
+//   - http.Handler: The metrics handler
+//
+// Example:
+//
+//	r.GET("/metrics/i18n", gin.WrapH(manager.MetricsHandler()))
+func (m *Manager) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		bw := bufio.NewWriter(w)
+		for _, v := range m.metrics.all() {
+			fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s counter\n", v.name, v.help, v.name)
+
+			values := v.snapshot()
+			keys := make([]string, 0, len(values))
+			for key := range values {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				bw.WriteString(v.name + "{")
+				for i, value := range strings.Split(key, labelSep) {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, "%s=\"%s\"", v.labels[i], labelEscaper.Replace(value))
+				}
+				fmt.Fprintf(bw, "} %d\n", values[key])
+			}
+		}
+		_ = bw.Flush()
+	})
+}
+
+// PublishExpvar publishes the metrics of the Manager as an expvar
+// variable, so they are served by the expvar handler at /debug/vars. The
+// variable maps each metric name to its counters, keyed by their label
+// values joined with ",". See MetricsHandler for the metrics.
+//
+// Parameters:
+//   - name: The name of the variable
+//
+// Returns:
+//   - error: An error if a variable with that name is already published
+//
+// Example:
+//
+//	if err := manager.PublishExpvar("i18n"); err != nil {
+//	    log.Println(err)
+//	}
+func (m *Manager) PublishExpvar(name string) error {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar: %s already published", name)
+	}
+
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		vars := make(map[string]map[string]int64)
+		for _, v := range m.metrics.all() {
+			values := make(map[string]int64)
+			for key, n := range v.snapshot() {
+				values[strings.ReplaceAll(key, labelSep, ",")] = n
+			}
+			vars[v.name] = values
+		}
+		return vars
+	}))
+
+	return nil
+}
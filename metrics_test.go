@@ -0,0 +1,125 @@
+package i18n
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	m, err := New(WithLangDir("./lang"), WithDefaultLang("en-US"), WithResolvers(
+		HeaderResolver("lang"),
+		ResolverFunc(func(c *gin.Context, _ *Manager) (string, bool) {
+			return "zh-CN", c.GetHeader("X-User") == "seakee"
+		}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.GET("/json", func(c *gin.Context) { m.JSON(c, 0, nil, nil) })
+	r.GET("/xml", func(c *gin.Context) { m.XML(c, 0, nil, nil) })
+	r.GET("/metrics", gin.WrapH(m.MetricsHandler()))
+
+	for _, tt := range []struct{ path, header, value string }{
+		{"/json", "lang", "zh-CN"},
+		{"/json", "lang", "zh-CN"},
+		{"/json", "X-User", "seakee"},
+		{"/xml", "lang", `fr"\`},
+		{"/json", "", ""},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		r.ServeHTTP(w, req)
+	}
+	m.Trans("pt-BR", "0")
+	m.Trans("zh-CN", "404")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `# HELP i18n_translations_total Translations served, by serving language.
+# TYPE i18n_translations_total counter
+i18n_translations_total{lang="en-US"} 3
+i18n_translations_total{lang="zh-CN"} 3
+# HELP i18n_fallbacks_total Translations served by a fallback language.
+# TYPE i18n_fallbacks_total counter
+i18n_fallbacks_total{lang="fr\"\\",served="en-US"} 1
+i18n_fallbacks_total{lang="pt-BR",served="en-US"} 1
+# HELP i18n_missing_total Missing translations, by requested language.
+# TYPE i18n_missing_total counter
+i18n_missing_total{lang="zh-CN"} 1
+# HELP i18n_resolver_total Request languages determined, by resolver.
+# TYPE i18n_resolver_total counter
+i18n_resolver_total{resolver="custom"} 1
+i18n_resolver_total{resolver="default"} 1
+i18n_resolver_total{resolver="header"} 3
+# HELP i18n_responses_total Responses written, by format.
+# TYPE i18n_responses_total counter
+i18n_responses_total{format="json"} 4
+i18n_responses_total{format="xml"} 1
+`, w.Body.String())
+}
+
+func TestMetricsSeriesLimit(t *testing.T) {
+	m, err := New(WithLangDir("./lang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < maxSeries+10; i++ {
+		m.Trans(fmt.Sprintf("x-%d", i), "0")
+	}
+	values := m.metrics.fallbacks.snapshot()
+	assert.Len(t, values, maxSeries+1)
+	assert.Equal(t, int64(10), values["other"+labelSep+"other"])
+}
+
+func TestPublishExpvar(t *testing.T) {
+	m, err := New(WithLangDir("./lang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Trans("zh-HK", "0")
+
+	// expvar names are process-wide, so each run publishes its own
+	name := fmt.Sprintf("i18n_test_%d", time.Now().UnixNano())
+	if !assert.NoError(t, m.PublishExpvar(name)) {
+		t.FailNow()
+	}
+	assert.Error(t, m.PublishExpvar(name))
+
+	var vars map[string]map[string]int64
+	assert.NoError(t, json.NewDecoder(strings.NewReader(expvar.Get(name).String())).Decode(&vars))
+	assert.Equal(t, map[string]int64{"en-US": 1}, vars["i18n_translations_total"])
+	assert.Equal(t, map[string]int64{"zh-HK,en-US": 1}, vars["i18n_fallbacks_total"])
+	assert.Equal(t, map[string]int64{}, vars["i18n_responses_total"])
+
+	// Concurrent publications of the same name fail instead of panicking
+	name += "_concurrent"
+	var wg sync.WaitGroup
+	var published atomic.Int32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if m.PublishExpvar(name) == nil {
+				published.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), published.Load())
+}
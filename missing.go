@@ -0,0 +1,191 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// MissingDefaultLang renders missing translations in the default
+	// language, or as the message code if it is missing there too
+	MissingDefaultLang MissingStrategy = "default"
+	// MissingCode renders missing translations as the message code
+	MissingCode MissingStrategy = "code"
+	// MissingMarker renders missing translations as "[[code]]", so they
+	// stand out when testing
+	MissingMarker MissingStrategy = "marker"
+
+	// maxMissingKeys bounds the registry of missing translations
+	maxMissingKeys = 10000
+)
+
+type (
+	// MissingStrategy is what a missing translation is rendered as, see
+	// WithMissingStrategy.
+	MissingStrategy string
+
+	// MissingHandler is called with the requested language and the message
+	// code of missing translations, see WithMissingHandler.
+	MissingHandler func(lang, key string)
+
+	// MissingKey is a translation found missing, see MissingKeys.
+	MissingKey struct {
+		Lang  string    // Requested language
+		Key   string    // Message code
+		Count int64     // Number of times the translation was missing
+		First time.Time // When the translation was first missing
+		Last  time.Time // When the translation was last missing
+	}
+
+	// missingRegistry records the missing translations
+	missingRegistry struct {
+		mu   sync.Mutex                // Guards keys
+		keys map[[2]string]*MissingKey // Missing translations keyed by language and code
+	}
+)
+
+// WithMissingHandler returns an Option that sets the handler notified of
+// missing translations. A translation is missing when the requested
+// language or one of its fallbacks (see WithFallbacks) is loaded but none
+// of them has the message, so it is rendered as set by
+// WithMissingStrategy. Languages that are not loaded are served the
+// default language without being reported, unless it lacks the message
+// too, which is reported for the default language. The handler is called
+// the first time each language and code is missing, until
+// ResetMissingKeys is called.
+//
+// Parameters:
+//   - h: The handler to notify
+//
+// Returns:
+//   - Option: A function that sets the missing handler in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithMissingHandler(func(lang, key string) {
+//	    log.Printf("missing translation: %s %s", lang, key)
+//	}))
+func WithMissingHandler(h MissingHandler) Option {
+	return func(o *option) {
+		o.missingHandler = h
+	}
+}
+
+// WithMissingStrategy returns an Option that sets what missing
+// translations are rendered as. The default is MissingDefaultLang.
+//
+// Parameters:
+//   - s: MissingDefaultLang, MissingCode or MissingMarker
+//
+// Returns:
+//   - Option: A function that sets the missing strategy in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithMissingStrategy(i18n.MissingMarker))
+func WithMissingStrategy(s MissingStrategy) Option {
+	return func(o *option) {
+		o.missingStrategy = s
+	}
+}
+
+// MissingKeys returns the translations found missing since the Manager
+// was created or ResetMissingKeys was called. At most 10000 are recorded.
+//
+// Returns:
+//   - []MissingKey: The missing translations, sorted by language and code
+//
+// Example:
+//
+//	for _, k := range manager.MissingKeys() {
+//	    fmt.Printf("%s %s: missing %d times\n", k.Lang, k.Key, k.Count)
+//	}
+func (m *Manager) MissingKeys() []MissingKey {
+	m.missing.mu.Lock()
+	keys := make([]MissingKey, 0, len(m.missing.keys))
+	for _, k := range m.missing.keys {
+		keys = append(keys, *k)
+	}
+	m.missing.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Lang != keys[j].Lang {
+			return keys[i].Lang < keys[j].Lang
+		}
+		return keys[i].Key < keys[j].Key
+	})
+
+	return keys
+}
+
+// ResetMissingKeys clears the missing translations, e.g. after they were
+// reported or translated.
+//
+// Example:
+//
+//	manager.ResetMissingKeys()
+func (m *Manager) ResetMissingKeys() {
+	m.missing.mu.Lock()
+	defer m.missing.mu.Unlock()
+
+	m.missing.keys = nil
+}
+
+// reportMissing records a missing translation and notifies the missing
+// handler the first time it is missing.
+//
+// Parameters:
+//   - ctx: The context of the translation, such as the gin context of a response
+//   - lang: The requested language
+//   - code: The message code
+func (m *Manager) reportMissing(ctx context.Context, lang string, code string) {
+	m.metrics.missing.inc(lang)
+
+	now := time.Now()
+	id := [2]string{lang, code}
+
+	added := false
+	m.missing.mu.Lock()
+	if k, ok := m.missing.keys[id]; ok {
+		k.Count++
+		k.Last = now
+	} else if len(m.missing.keys) < maxMissingKeys {
+		if m.missing.keys == nil {
+			m.missing.keys = make(map[[2]string]*MissingKey)
+		}
+		m.missing.keys[id] = &MissingKey{Lang: lang, Key: code, Count: 1, First: now, Last: now}
+		added = true
+	}
+	m.missing.mu.Unlock()
+
+	if !added {
+		return
+	}
+	m.log(ctx, slog.LevelWarn, logMissing, m.logKey(lang, code)...)
+	if m.opt.missingHandler != nil {
+		m.opt.missingHandler(lang, code)
+	}
+}
+
+// missingText returns what a missing translation is rendered as when it
+// is not rendered in the default language.
+//
+// Parameters:
+//   - code: The message code
+//
+// Returns:
+//   - string: The message code, or "[[code]]" with MissingMarker
+func (m *Manager) missingText(code string) string {
+	if m.opt.missingStrategy == MissingMarker {
+		return "[[" + code + "]]"
+	}
+
+	return code
+}
@@ -0,0 +1,86 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMissingHandler(t *testing.T) {
+	var reported []string
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{"0": "ok", "1": "one", "1001": {"one": "%s file", "other": "%s files"}}`,
+		"zh-CN.json": `{"0": "成功"}`,
+	},
+		WithDefaultLang("en-US"),
+		WithFallbacks(map[string][]string{"zh-HK": {"zh-CN"}}),
+		WithMissingHandler(func(lang, key string) {
+			reported = append(reported, lang+" "+key)
+		}),
+	)
+
+	// Found in the requested language or its fallbacks
+	assert.Equal(t, "成功", m.Trans("zh-CN", "0"))
+	assert.Equal(t, "成功", m.Trans("zh-HK", "0"))
+	assert.Empty(t, reported)
+
+	// Languages that are not loaded are served the default language
+	for _, lang := range []string{"fr", "xx-junk", "ru-RU"} {
+		assert.Equal(t, "ok", m.Trans(lang, "0"))
+	}
+	assert.Empty(t, reported)
+
+	// Missing translations are rendered in the default language by default
+	assert.Equal(t, "one", m.Trans("zh-CN", "1"))
+	assert.Equal(t, "one", m.Trans("zh-HK", "1"))
+	assert.Equal(t, "1234", m.Trans("zh-CN", "1234"))
+	assert.Equal(t, "1234", m.Trans("zh-CN", "1234"))
+	assert.Equal(t, "1234", m.Trans("en-US", "1234"))
+	// Codes missing for languages that are not loaded are reported for the default language
+	assert.Equal(t, "1234", m.Trans("fr", "1234"))
+	assert.Equal(t, "2 files", m.TransPlural("zh-CN", "1001", 2, "2"))
+	assert.Equal(t, []string{"zh-CN 1", "zh-HK 1", "zh-CN 1234", "en-US 1234", "zh-CN 1001"}, reported)
+
+	keys := m.MissingKeys()
+	if assert.Len(t, keys, 5) {
+		assert.Equal(t, MissingKey{Lang: "en-US", Key: "1234", Count: 2, First: keys[0].First, Last: keys[0].Last}, keys[0])
+		assert.Equal(t, MissingKey{Lang: "zh-CN", Key: "1234", Count: 2, First: keys[3].First, Last: keys[3].Last}, keys[3])
+		assert.False(t, keys[3].Last.Before(keys[3].First))
+	}
+
+	// Resetting reports them again
+	m.ResetMissingKeys()
+	assert.Empty(t, m.MissingKeys())
+	m.Trans("zh-CN", "1234")
+	assert.Len(t, reported, 6)
+}
+
+func TestMissingStrategy(t *testing.T) {
+	for _, tt := range []struct {
+		strategy MissingStrategy
+		de       string
+		unknown  string
+		missing  int
+	}{
+		{MissingDefaultLang, "two", "1234", 3},
+		{MissingCode, "2", "1234", 2},
+		{MissingMarker, "[[2]]", "[[1234]]", 2},
+	} {
+		m := newTestManager(t, map[string]string{
+			"en-US.json": `{"0": "ok", "1": "one", "2": "two"}`,
+			"zh-CN.json": `{"0": "成功", "1": "一"}`,
+			"de.json":    `{"0": "gut"}`,
+		}, WithDefaultLang("en-US"), WithMissingStrategy(tt.strategy), WithFallbacks(map[string][]string{"de": {"en-US", "zh-CN"}}))
+
+		assert.Equal(t, tt.de, m.Trans("de", "2"), tt.strategy)
+		assert.Equal(t, tt.unknown, m.Trans("en-US", "1234"), tt.strategy)
+		assert.Equal(t, "ok", m.Trans("fr", "0"), tt.strategy)
+		assert.Equal(t, "成功", m.Trans("zh-CN", "0"), tt.strategy)
+		// Fallbacks after the default language are still tried
+		if tt.strategy != MissingDefaultLang {
+			assert.Equal(t, "一", m.Trans("de", "1"), tt.strategy)
+		} else {
+			assert.Equal(t, "one", m.Trans("de", "1"), tt.strategy)
+		}
+		assert.Len(t, m.MissingKeys(), tt.missing, tt.strategy)
+	}
+}
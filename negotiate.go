@@ -0,0 +1,149 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"golang.org/x/text/language"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// langMatcher selects the best supported language for a list of
+// user preferences using BCP 47 matching.
+type langMatcher struct {
+	matcher language.Matcher // Matcher built from the supported language tags
+	langs   []string         // Language codes in the same order as the matcher's tags
+}
+
+// newLangMatcher builds a langMatcher from the loaded language list.
+// Language codes that are not valid BCP 47 tags are skipped, they can
+// still be selected by an exact "lang" header or User-Agent parameter.
+//
+// Parameters:
+//   - langList: A map of language codes to their message maps
+//
+// Returns:
+//   - *langMatcher: The matcher for the supported languages
+func newLangMatcher(langList map[string]map[string]string) *langMatcher {
+	codes := make([]string, 0, len(langList))
+	for code := range langList {
+		codes = append(codes, code)
+	}
+	// Sort the codes so matching is deterministic between runs
+	sort.Strings(codes)
+
+	lm := &langMatcher{}
+	var tags []language.Tag
+	for _, code := range codes {
+		tag, err := language.Parse(code)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, tag)
+		lm.langs = append(lm.langs, code)
+	}
+
+	if len(tags) > 0 {
+		lm.matcher = language.NewMatcher(tags)
+	}
+
+	return lm
+}
+
+// match returns the supported language code that best matches the
+// given preferred tags, or false if none of them is close enough.
+//
+// Parameters:
+//   - prefs: The preferred language tags, most preferred first
+//
+// Returns:
+//   - string: The matched language code
+//   - bool: true if a supported language matched, false otherwise
+func (lm *langMatcher) match(prefs ...language.Tag) (string, bool) {
+	if lm.matcher == nil || len(prefs) == 0 {
+		return "", false
+	}
+
+	_, index, confidence := lm.matcher.Match(prefs...)
+	if confidence == language.No {
+		return "", false
+	}
+
+	return lm.langs[index], true
+}
+
+// matchAcceptLanguage returns the supported language code that best
+// matches an Accept-Language header value.
+//
+// Parameters:
+//   - header: The raw Accept-Language header value
+//
+// Returns:
+//   - string: The matched language code
+//   - bool: true if a supported language matched, false otherwise
+func (lm *langMatcher) matchAcceptLanguage(header string) (string, bool) {
+	return lm.match(parseAcceptLanguage(header)...)
+}
+
+// parseAcceptLanguage parses an Accept-Language header value into a list
+// of language tags ranked by quality. Unlike language.ParseAcceptLanguage
+// it skips malformed entries and wildcards instead of rejecting the whole
+// header, since browsers and proxies are not always strict.
+//
+// Parameters:
+//   - header: The raw Accept-Language header value, e.g. "fr-CA,fr;q=0.9,en;q=0.8"
+//
+// Returns:
+//   - []language.Tag: The accepted tags, highest quality first
+func parseAcceptLanguage(header string) []language.Tag {
+	type weighted struct {
+		tag language.Tag
+		q   float64
+	}
+
+	var list []weighted
+	for _, entry := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		name = strings.TrimSpace(name)
+		if name == "" || name == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = v
+			}
+		}
+
+		// A quality of 0 means "not acceptable"
+		if q <= 0 {
+			continue
+		}
+
+		tag, err := language.Parse(name)
+		if err != nil {
+			continue
+		}
+		list = append(list, weighted{tag: tag, q: q})
+	}
+
+	// Keep the header order for entries with the same quality
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].q > list[j].q
+	})
+
+	tags := make([]language.Tag, len(list))
+	for i, w := range list {
+		tags[i] = w.tag
+	}
+
+	return tags
+}
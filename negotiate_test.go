@@ -0,0 +1,55 @@
+package i18n
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tags := parseAcceptLanguage("fr;q=0.9, en;q=0.8, fr-CA, *;q=0.5, de;q=0, !!bad")
+
+	var got []string
+	for _, tag := range tags {
+		got = append(got, tag.String())
+	}
+
+	assert.Equal(t, []string{"fr-CA", "fr", "en"}, got)
+}
+
+func TestLangNegotiation(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		ua     string
+		want   string
+	}{
+		{name: "simplified chinese", header: map[string]string{"Accept-Language": "zh-Hans-CN"}, want: "zh-CN"},
+		{name: "bare english", header: map[string]string{"Accept-Language": "en"}, want: "en-US"},
+		{name: "quality ranking", header: map[string]string{"Accept-Language": "fr-CA,zh;q=0.9,en;q=0.8"}, want: "zh-CN"},
+		{name: "no match", header: map[string]string{"Accept-Language": "fr-CA,fr;q=0.9"}, want: "en-US"},
+		{name: "lang header wins", header: map[string]string{"lang": "zh-CN", "Accept-Language": "en"}, want: "zh-CN"},
+		{name: "user agent wins", header: map[string]string{"Accept-Language": "en"}, ua: "app/1.0;lang=zh-CN", want: "zh-CN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.header {
+				c.Request.Header.Set(k, v)
+			}
+			if tt.ua != "" {
+				c.Request.Header.Set("User-Agent", tt.ua)
+			}
+
+			assert.Equal(t, tt.want, m.lang(c))
+		})
+	}
+}
@@ -0,0 +1,395 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// overrideOrigin is the source name reported by Origin for runtime overrides
+const overrideOrigin = "runtime"
+
+type (
+	// Overrides are the changes made to the messages at runtime with Set,
+	// SetMany, Delete and AddLanguage. They are applied over the messages of
+	// the sources, and kept across reloads.
+	Overrides struct {
+		Messages map[string]map[string]string `json:"messages,omitempty"` // Set messages keyed by language and code
+		Deleted  map[string]map[string]bool   `json:"deleted,omitempty"`  // Deleted codes keyed by language
+	}
+
+	// OverrideStore persists runtime overrides so they survive restarts,
+	// see WithOverrideStore.
+	OverrideStore interface {
+		// Load returns the saved overrides. ctx expires after the load
+		// timeout (see WithLoadTimeout).
+		Load(ctx context.Context) (Overrides, error)
+		// Save persists the overrides after every change. The change is
+		// rejected if it cannot be saved. ctx expires after the load
+		// timeout.
+		Save(ctx context.Context, o Overrides) error
+	}
+
+	// fileOverrideStore keeps the overrides in a JSON file
+	fileOverrideStore struct {
+		path string // Path of the JSON file
+	}
+)
+
+// WithOverrideStore returns an Option that persists runtime overrides.
+// The saved overrides are loaded by New and applied over the sources,
+// and every change is saved before it takes effect.
+//
+// Parameters:
+//   - s: The store of the overrides
+//
+// Returns:
+//   - Option: A function that sets the override store in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithOverrideStore(i18n.FileOverrideStore("./data/i18n-overrides.json")))
+func WithOverrideStore(s OverrideStore) Option {
+	return func(o *option) {
+		o.overrideStore = s
+	}
+}
+
+// FileOverrideStore returns an OverrideStore keeping the overrides in a
+// JSON file. A missing file holds no overrides.
+//
+// Parameters:
+//   - path: The path of the JSON file
+//
+// Returns:
+//   - OverrideStore: The file store
+func FileOverrideStore(path string) OverrideStore {
+	return &fileOverrideStore{path: path}
+}
+
+// Load implements OverrideStore.
+func (s *fileOverrideStore) Load(context.Context) (Overrides, error) {
+	var o Overrides
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return o, err
+	}
+	if err = json.Unmarshal(data, &o); err != nil {
+		return o, fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	return o, nil
+}
+
+// Save implements OverrideStore.
+func (s *fileOverrideStore) Save(_ context.Context, o Overrides) error {
+	return writeJSONFile(s.path, o)
+}
+
+// Set adds or replaces a message of a loaded language at runtime. The
+// message is validated like the language files: ICU messages must parse,
+// and the verbs of printf messages must be well-formed and consume as many
+// arguments as the same key in the default language, or as the message it
+// replaces. Plural forms are set with keys like "1001.one".
+//
+// Parameters:
+//   - lang: The language code
+//   - code: The message code
+//   - msg: The message
+//
+// Returns:
+//   - error: An error if the language is not loaded, the message is invalid or cannot be saved
+//
+// Example:
+//
+//	if err := manager.Set("en-US", "500", "Something went wrong"); err != nil {
+//	    log.Println(err)
+//	}
+func (m *Manager) Set(lang string, code string, msg string) error {
+	return m.SetMany(lang, map[string]string{code: msg})
+}
+
+// SetMany adds or replaces several messages of a loaded language at
+// runtime, see Set. Either all messages are set or, if one is invalid,
+// none are.
+//
+// Parameters:
+//   - lang: The language code
+//   - messages: The messages keyed by message code
+//
+// Returns:
+//   - error: An error if the language is not loaded, a message is invalid or cannot be saved
+//
+// Example:
+//
+//	err := manager.SetMany("en-US", map[string]string{
+//	    "1001.one":   "%s item",
+//	    "1001.other": "%s items",
+//	})
+func (m *Manager) SetMany(lang string, messages map[string]string) error {
+	return m.override(func(langs map[string]map[string]string, o *Overrides) error {
+		if _, ok := langs[lang]; !ok {
+			return fmt.Errorf("%s: language not loaded", lang)
+		}
+
+		return m.setOverrides(langs, o, lang, messages)
+	})
+}
+
+// AddLanguage adds a language with its messages at runtime. The messages
+// are validated like those of Set.
+//
+// Parameters:
+//   - lang: The BCP 47 language code, which must not be loaded yet
+//   - messages: The messages keyed by message code
+//
+// Returns:
+//   - error: An error if the language is invalid or loaded, a message is invalid or cannot be saved
+//
+// Example:
+//
+//	err := manager.AddLanguage("fr", map[string]string{"0": "d'accord"})
+func (m *Manager) AddLanguage(lang string, messages map[string]string) error {
+	if !isLangTag(lang) {
+		return fmt.Errorf("%s: invalid language code", lang)
+	}
+	if len(messages) == 0 {
+		return fmt.Errorf("%s: no messages", lang)
+	}
+
+	return m.override(func(langs map[string]map[string]string, o *Overrides) error {
+		if _, ok := langs[lang]; ok {
+			return fmt.Errorf("%s: language already loaded", lang)
+		}
+
+		return m.setOverrides(langs, o, lang, messages)
+	})
+}
+
+// Delete removes a message of a loaded language at runtime, including
+// all plural forms of a plural message. The message stays removed across
+// reloads, until it is set again. Deleting a message that is not loaded
+// does nothing.
+//
+// Parameters:
+//   - lang: The language code
+//   - code: The message code
+//
+// Returns:
+//   - error: An error if the language is not loaded or the change cannot be saved
+//
+// Example:
+//
+//	err := manager.Delete("en-US", "500")
+func (m *Manager) Delete(lang string, code string) error {
+	return m.override(func(langs map[string]map[string]string, o *Overrides) error {
+		messages, ok := langs[lang]
+		if !ok {
+			return fmt.Errorf("%s: language not loaded", lang)
+		}
+
+		for key := range messages {
+			if c, _, _ := cutPluralCategory(key); key != code && c != code {
+				continue
+			}
+			delete(o.Messages[lang], key)
+			if o.Deleted == nil {
+				o.Deleted = make(map[string]map[string]bool)
+			}
+			if o.Deleted[lang] == nil {
+				o.Deleted[lang] = make(map[string]bool)
+			}
+			o.Deleted[lang][key] = true
+		}
+
+		return nil
+	})
+}
+
+// Overrides returns a copy of the runtime overrides.
+//
+// Returns:
+//   - Overrides: The messages set and deleted at runtime
+//
+// Example:
+//
+//	for lang, messages := range manager.Overrides().Messages {
+//	    fmt.Printf("%s: %d messages overridden\n", lang, len(messages))
+//	}
+func (m *Manager) Overrides() Overrides {
+	m.loadMu.Lock()
+	defer m.loadMu.Unlock()
+
+	return m.overrides.clone()
+}
+
+// override applies a change to a copy of the runtime overrides, saves them
+// to the store, if any, and swaps in a catalog with the overrides applied.
+//
+// Parameters:
+//   - change: The change, validated against the messages of the active catalog
+//
+// Returns:
+//   - error: An error if the change is invalid or cannot be saved
+func (m *Manager) override(change func(langs map[string]map[string]string, o *Overrides) error) error {
+	m.loadMu.Lock()
+	defer m.loadMu.Unlock()
+
+	cur := m.catalog.Load()
+	o := m.overrides.clone()
+	if err := change(cur.langs, &o); err != nil {
+		return err
+	}
+
+	langList, origins := copyLangList(cur.langs), copyLangList(cur.origins)
+	o.apply(langList, origins)
+	patterns, err := m.compileMessages(langList, cur.icu)
+	if err != nil {
+		return err
+	}
+
+	if m.opt.overrideStore != nil {
+		ctx, cancel := m.loadContext()
+		err = m.opt.overrideStore.Save(ctx, o)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	m.overrides = o
+	m.catalog.Store(&catalog{langs: langList, origins: origins, matcher: newLangMatcher(langList), icu: patterns})
+
+	return nil
+}
+
+// setOverrides validates messages of a language and records them as set.
+//
+// Parameters:
+//   - langs: The messages of the active catalog
+//   - o: The overrides to record the messages in
+//   - lang: The language code
+//   - messages: The messages keyed by message code
+//
+// Returns:
+//   - error: An error if a message is invalid
+func (m *Manager) setOverrides(langs map[string]map[string]string, o *Overrides, lang string, messages map[string]string) error {
+	for code, msg := range messages {
+		if err := m.checkMessage(langs, lang, code, msg); err != nil {
+			return err
+		}
+	}
+
+	if o.Messages == nil {
+		o.Messages = make(map[string]map[string]string)
+	}
+	if o.Messages[lang] == nil {
+		o.Messages[lang] = make(map[string]string, len(messages))
+	}
+	for code, msg := range messages {
+		o.Messages[lang][code] = msg
+		delete(o.Deleted[lang], code)
+	}
+
+	return nil
+}
+
+// checkMessage validates a message set at runtime.
+//
+// Parameters:
+//   - langs: The messages of the active catalog
+//   - lang: The language code
+//   - code: The message code
+//   - msg: The message
+//
+// Returns:
+//   - error: An error if the code is reserved or the message is invalid
+func (m *Manager) checkMessage(langs map[string]map[string]string, lang string, code string, msg string) error {
+	if code == "" || isMetaKey(code) {
+		return fmt.Errorf("%s: invalid key %q", lang, code)
+	}
+
+	if m.formatOf(langs[lang]) == ICUFormat {
+		if _, err := icu(m.catalog.Load(), msg); err != nil {
+			return fmt.Errorf("%s: key %q: %w", lang, code, err)
+		}
+		return nil
+	}
+
+	verbs, err := countVerbs(msg)
+	if err != nil {
+		return &FormatError{Lang: lang, Key: code, Msg: msg, Err: err}
+	}
+
+	// Compare with the default language, or the message being replaced
+	ref := m.DefaultLang()
+	refMsg, ok := langs[ref][code]
+	if ref == lang || !ok || m.formatOf(langs[ref]) != PrintfFormat {
+		ref = lang
+		refMsg, ok = langs[lang][code]
+	}
+	if !ok {
+		return nil
+	}
+	if refVerbs, err := countVerbs(refMsg); err == nil && refVerbs != verbs {
+		return &FormatError{Lang: lang, Key: code, Msg: msg, Verbs: verbs, Args: refVerbs, RefLang: ref}
+	}
+
+	return nil
+}
+
+// apply applies the overrides to merged messages of the sources.
+//
+// Parameters:
+//   - langList: The messages keyed by language and code, modified in place
+//   - origins: The name of the source of each message, modified in place
+func (o Overrides) apply(langList map[string]map[string]string, origins map[string]map[string]string) {
+	for lang, codes := range o.Deleted {
+		for code := range codes {
+			delete(langList[lang], code)
+			delete(origins[lang], code)
+		}
+	}
+
+	for lang, messages := range o.Messages {
+		if langList[lang] == nil {
+			langList[lang] = make(map[string]string, len(messages))
+			origins[lang] = make(map[string]string, len(messages))
+		}
+		for code, msg := range messages {
+			langList[lang][code] = msg
+			origins[lang][code] = overrideOrigin
+		}
+	}
+}
+
+// clone returns a deep copy of the overrides.
+//
+// Returns:
+//   - Overrides: The copy
+func (o Overrides) clone() Overrides {
+	var cp Overrides
+	if o.Messages != nil {
+		cp.Messages = copyLangList(o.Messages)
+	}
+	if o.Deleted != nil {
+		cp.Deleted = make(map[string]map[string]bool, len(o.Deleted))
+		for lang, codes := range o.Deleted {
+			cp.Deleted[lang] = make(map[string]bool, len(codes))
+			for code := range codes {
+				cp.Deleted[lang][code] = true
+			}
+		}
+	}
+
+	return cp
+}
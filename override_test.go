@@ -0,0 +1,155 @@
+package i18n
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	dir := writeLangDir(t, map[string]string{
+		"en-US.json": `{"0": "ok", "1000": "Hello,%s!", "1001": {"one": "%s item", "other": "%s items"}}`,
+		"zh-CN.json": `{"0": "成功", "1000": "你好,%s!"}`,
+	})
+	m, err := New(WithLangDir(dir), WithDefaultLang("en-US"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, m.Set("en-US", "0", "all good"))
+	assert.NoError(t, m.SetMany("zh-CN", map[string]string{"1000": "您好,%s!", "404": "未找到"}))
+	assert.Equal(t, "all good", m.Trans("en-US", "0"))
+	assert.Equal(t, "您好,Tom!", m.Trans("zh-CN", "1000", "Tom"))
+	assert.Equal(t, "未找到", m.Trans("zh-CN", "404"))
+	origin, _ := m.Origin("en-US", "0")
+	assert.Equal(t, "runtime", origin)
+
+	assert.NoError(t, m.AddLanguage("fr", map[string]string{"0": "d'accord", "1000": "Bonjour, %s !"}))
+	assert.Equal(t, "Bonjour, Tom !", m.Trans("fr", "1000", "Tom"))
+	assert.True(t, m.LangExist("fr"))
+
+	assert.NoError(t, m.Delete("en-US", "1001"))
+	assert.Equal(t, "1001", m.TransPlural("en-US", "1001", 2, "2"))
+	assert.NotContains(t, m.Messages("en-US"), "1001.one")
+	assert.NoError(t, m.Delete("en-US", "404"))
+
+	// Overrides are kept across reloads
+	assert.NoError(t, m.Reload())
+	assert.Equal(t, "all good", m.Trans("en-US", "0"))
+	assert.Equal(t, "d'accord", m.Trans("fr", "0"))
+	assert.Equal(t, "1001", m.TransPlural("en-US", "1001", 2, "2"))
+
+	// Setting a deleted message restores it
+	assert.NoError(t, m.Set("en-US", "1001.other", "%s things"))
+	assert.Equal(t, "2 things", m.TransPlural("en-US", "1001", 2, "2"))
+
+	assert.Equal(t, Overrides{
+		Messages: map[string]map[string]string{
+			"en-US": {"0": "all good", "1001.other": "%s things"},
+			"zh-CN": {"1000": "您好,%s!", "404": "未找到"},
+			"fr":    {"0": "d'accord", "1000": "Bonjour, %s !"},
+		},
+		Deleted: map[string]map[string]bool{"en-US": {"1001.one": true}},
+	}, m.Overrides())
+}
+
+func TestSetInvalid(t *testing.T) {
+	m, err := New(WithLangDir("./lang"), WithDefaultLang("en-US"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		err  error
+	}{
+		{"unknown language", m.Set("fr", "0", "d'accord")},
+		{"reserved key", m.Set("en-US", "@@format", "icu")},
+		{"empty key", m.Set("en-US", "", "x")},
+		{"malformed verb", m.Set("en-US", "0", "ok %")},
+		{"verbs differ from default language", m.Set("zh-CN", "1000", "你好,%s!")},
+		{"verbs differ from replaced message", m.Set("en-US", "1000", "Hello!")},
+		{"language loaded", m.AddLanguage("zh-CN", map[string]string{"0": "成功"})},
+		{"invalid language", m.AddLanguage("not a language", map[string]string{"0": "ok"})},
+		{"no messages", m.AddLanguage("fr", nil)},
+		{"delete in unknown language", m.Delete("fr", "0")},
+	} {
+		assert.Error(t, tt.err, tt.name)
+	}
+
+	var ferr *FormatError
+	if assert.True(t, errors.As(m.Set("zh-CN", "1000", "你好"), &ferr)) {
+		assert.Equal(t, "en-US", ferr.RefLang)
+	}
+
+	// A batch with an invalid message is rejected as a whole
+	assert.Error(t, m.SetMany("en-US", map[string]string{"0": "all good", "500": "%[0]d"}))
+	assert.Equal(t, "ok", m.Trans("en-US", "0"))
+	assert.Equal(t, Overrides{}, m.Overrides())
+
+	// ICU messages must parse
+	icu := newTestManager(t, map[string]string{"en-US.json": `{"0": "ok"}`}, WithMessageFormat(ICUFormat))
+	assert.Error(t, icu.Set("en-US", "0", "{count, plural, one {# item}"))
+}
+
+func TestOverrideStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	store := FileOverrideStore(path)
+
+	m, err := New(WithLangDir("./lang"), WithOverrideStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, m.Set("en-US", "0", "all good"))
+	assert.NoError(t, m.Delete("en-US", "500"))
+	assert.NoError(t, m.AddLanguage("fr", map[string]string{"0": "d'accord"}))
+
+	// Overrides survive restarts
+	m, err = New(WithLangDir("./lang"), WithOverrideStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "all good", m.Trans("en-US", "0"))
+	assert.Equal(t, "500", m.Trans("en-US", "500"))
+	assert.Equal(t, "d'accord", m.Trans("fr", "0"))
+
+	// Changes that cannot be saved are rejected
+	m, err = New(WithLangDir("./lang"), WithOverrideStore(FileOverrideStore(filepath.Join(t.TempDir(), "missing", "o.json"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Error(t, m.Set("en-US", "0", "all good"))
+	assert.Equal(t, "ok", m.Trans("en-US", "0"))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{`), 0o644))
+	_, err = New(WithLangDir("./lang"), WithOverrideStore(store))
+	assert.Error(t, err)
+}
+
+func TestSetConcurrent(t *testing.T) {
+	m, err := New(WithLangDir("./lang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			code := string(rune('a' + i))
+			assert.NoError(t, m.Set("en-US", code, code))
+			_ = m.Trans("en-US", code)
+			_ = m.Reload()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 8; i++ {
+		code := string(rune('a' + i))
+		assert.Equal(t, code, m.Trans("en-US", code))
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"fmt"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+	"strings"
+	"time"
+)
+
+// defaultTimeLayout is the layout for time values in languages without a
+// layout of their own
+const defaultTimeLayout = "2006-01-02 15:04:05"
+
+// defaultTimeLayouts holds the built-in time layouts keyed by language code.
+// Regional variants without a layout use the layout of their parent language.
+var defaultTimeLayouts = map[string]string{
+	"en":    "Jan 2, 2006 3:04 PM",
+	"en-GB": "2 Jan 2006 15:04",
+	"zh":    "2006年1月2日 15:04",
+	"ja":    "2006年1月2日 15:04",
+	"ko":    "2006년 1월 2일 15:04",
+	"de":    "02.01.2006 15:04",
+	"ru":    "02.01.2006 15:04",
+	"pl":    "02.01.2006 15:04",
+	"fr":    "02/01/2006 15:04",
+	"es":    "02/01/2006 15:04",
+	"it":    "02/01/2006 15:04",
+	"pt":    "02/01/2006 15:04",
+}
+
+// WithTimeLayouts returns an Option that sets the layouts used to format
+// time.Time arguments of named placeholders, keyed by language code. They
+// take precedence over the built-in layouts; regional variants without a
+// layout use the layout of their parent language.
+//
+// Parameters:
+//   - layouts: A map of language codes to time.Format layouts
+//
+// Returns:
+//   - Option: A function that sets the time layouts in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithTimeLayouts(map[string]string{
+//	    "en-US": "01/02/2006",
+//	}))
+func WithTimeLayouts(layouts map[string]string) Option {
+	return func(o *option) {
+		o.timeLayouts = layouts
+	}
+}
+
+// replaceNamed replaces the named placeholders of a message, such as
+// "{name}", with the formatted values of args. Placeholders without a
+// matching argument, and braces that do not form a placeholder, are kept.
+//
+// Parameters:
+//   - lang: The language code used to format the values
+//   - msg: The message template
+//   - args: The named arguments
+//   - escape: Whether "%" in the values is escaped, for a template later formatted by fmt.Sprintf
+//
+// Returns:
+//   - string: The message with the placeholders replaced
+func (m *Manager) replaceNamed(lang string, msg string, args map[string]interface{}, escape bool) string {
+	if len(args) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(msg, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(msg[open:], '}')
+		if end < 0 {
+			break
+		}
+		end += open
+
+		b.WriteString(msg[:open])
+		name := msg[open+1 : end]
+		if v, ok := args[name]; ok && isPlaceholderName(name) {
+			value := m.formatValue(lang, v)
+			if escape {
+				value = strings.ReplaceAll(value, "%", "%%")
+			}
+			b.WriteString(value)
+			msg = msg[end+1:]
+		} else {
+			// Not a placeholder, keep the brace and continue after it
+			b.WriteByte('{')
+			msg = msg[open+1:]
+		}
+	}
+	b.WriteString(msg)
+
+	return b.String()
+}
+
+// isPlaceholderName reports whether name may be used as a named placeholder:
+// a non-empty run of letters, digits, "_", "-" and ".".
+//
+// Parameters:
+//   - name: The text between the braces
+//
+// Returns:
+//   - bool: true if name is a valid placeholder name, false otherwise
+func isPlaceholderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// formatValue formats a placeholder argument for the given language:
+// numbers use the language's digit grouping and decimal separator, and
+// at most three fraction digits like the CLDR decimal format,
+// time.Time values use the language's time layout (see WithTimeLayouts)
+// and other values are formatted with fmt.Sprint.
+//
+// Parameters:
+//   - lang: The language code
+//   - v: The value to format
+//
+// Returns:
+//   - string: The formatted value
+func (m *Manager) formatValue(lang string, v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case time.Time:
+		return val.Format(m.timeLayout(lang))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return message.NewPrinter(tag).Sprint(number.Decimal(val))
+	}
+
+	return fmt.Sprint(v)
+}
+
+// timeLayout returns the time layout of a language, looking up the
+// configured layouts before the built-in ones, and parent languages after
+// the language itself.
+//
+// Parameters:
+//   - lang: The language code
+//
+// Returns:
+//   - string: The time.Format layout
+func (m *Manager) timeLayout(lang string) string {
+	for _, l := range append([]string{lang}, parentLangs(lang)...) {
+		if layout, ok := m.opt.timeLayouts[l]; ok {
+			return layout
+		}
+		if layout, ok := defaultTimeLayouts[l]; ok {
+			return layout
+		}
+	}
+
+	return defaultTimeLayout
+}
@@ -0,0 +1,37 @@
+package i18n
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTransMap(t *testing.T) {
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{"1": "Hello, {name}! Account: {account}", "2": "Balance {balance} on {date}", "3": "{unknown} {} {not a name} {name}", "4": "{rate} off, %s left"}`,
+		"de-DE.json": `{"2": "Kontostand {balance} am {date}"}`,
+		"zh-CN.json": `{"1": "账号:{account}, 你好,{name}!"}`,
+	}, WithTimeLayouts(map[string]string{"zh-CN": "2006/01/02"}))
+
+	date := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+	args := map[string]interface{}{"name": "Seakee", "account": 18888888888, "balance": 1234567.5, "date": date}
+
+	assert.Equal(t, "Hello, Seakee! Account: 18,888,888,888", m.TransMap("en-US", "1", args))
+	assert.Equal(t, "账号:18,888,888,888, 你好,Seakee!", m.TransMap("zh-CN", "1", args))
+	assert.Equal(t, "Balance 1,234,567.5 on Mar 5, 2024 2:30 PM", m.TransMap("en-US", "2", args))
+	assert.Equal(t, "Kontostand 1.234.567,5 am 05.03.2024 14:30", m.TransMap("de-DE", "2", args))
+	assert.Equal(t, "{unknown} {} {not a name} Seakee", m.TransMap("en-US", "3", args))
+	assert.Equal(t, "2024/03/05", m.formatValue("zh-CN", date))
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	res := m.result(c, 1, Data{Args: map[string]interface{}{"name": "Seakee", "account": "188"}}, nil)
+	assert.Equal(t, "Hello, Seakee! Account: 188", res.Msg)
+
+	// "%" in named values survives positional formatting
+	res = m.result(c, 4, Data{Params: []string{"3"}, Args: map[string]interface{}{"rate": "100%"}}, nil)
+	assert.Equal(t, "100% off, 3 left", res.Msg)
+	assert.Equal(t, "100% off, %s left", m.TransMap("en-US", "4", map[string]interface{}{"rate": "100%"}))
+}
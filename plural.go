@@ -0,0 +1,218 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"strconv"
+	"strings"
+)
+
+// pluralSep separates a message code from its plural category in the
+// catalog, e.g. the "one" form of code "1001" is stored as "1001.one".
+const pluralSep = "."
+
+// pluralForms maps the CLDR plural forms to their category names.
+var pluralForms = [...]string{
+	plural.Other: "other",
+	plural.Zero:  "zero",
+	plural.One:   "one",
+	plural.Two:   "two",
+	plural.Few:   "few",
+	plural.Many:  "many",
+}
+
+// isPluralCategory reports whether name is a CLDR plural category
+// (zero, one, two, few, many or other).
+//
+// Parameters:
+//   - name: The name to check
+//
+// Returns:
+//   - bool: true if name is a plural category, false otherwise
+func isPluralCategory(name string) bool {
+	for _, c := range pluralForms {
+		if c == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pluralCategory returns the CLDR cardinal plural category of count in
+// the given language. Languages that are not valid BCP 47 tags, and counts
+// that are not numbers, use the "other" category.
+//
+// Parameters:
+//   - lang: The language code whose plural rules apply
+//   - count: An integer, a floating-point number or a decimal string
+//
+// Returns:
+//   - string: The plural category, e.g. "one" or "few"
+func pluralCategory(lang string, count interface{}) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return pluralForms[plural.Other]
+	}
+
+	i, v, w, f, t, ok := pluralOperands(count)
+	if !ok {
+		return pluralForms[plural.Other]
+	}
+
+	return pluralForms[plural.Cardinal.MatchPlural(tag, i, v, w, f, t)]
+}
+
+// pluralCategoriesOf returns the CLDR cardinal plural categories used by a
+// language, in the order zero, one, two, few, many, other.
+//
+// Parameters:
+//   - lang: The language code
+//
+// Returns:
+//   - []string: The plural categories of the language
+func pluralCategoriesOf(lang string) []string {
+	used := make(map[string]bool, len(pluralForms))
+	for n := 0; n <= 1000; n++ {
+		used[pluralCategory(lang, n)] = true
+	}
+	for _, fraction := range []string{"0.5", "1.5", "2.5", "5.5", "1000000.5"} {
+		used[pluralCategory(lang, fraction)] = true
+	}
+	used[pluralCategory(lang, 1000000)] = true
+
+	categories := make([]string, 0, len(used))
+	for _, form := range []plural.Form{plural.Zero, plural.One, plural.Two, plural.Few, plural.Many, plural.Other} {
+		if used[pluralForms[form]] {
+			categories = append(categories, pluralForms[form])
+		}
+	}
+
+	return categories
+}
+
+// pluralOperands computes the CLDR plural operands of a number.
+// Visible fraction digits are significant, so the string "1.50" has
+// different operands than the number 1.5.
+//
+// Parameters:
+//   - count: An integer, a floating-point number or a decimal string
+//
+// Returns:
+//   - i: The integer digits
+//   - v: The number of visible fraction digits, with trailing zeros
+//   - w: The number of visible fraction digits, without trailing zeros
+//   - f: The visible fraction digits, with trailing zeros
+//   - t: The visible fraction digits, without trailing zeros
+//   - ok: false if count is not a number
+func pluralOperands(count interface{}) (i, v, w, f, t int, ok bool) {
+	var s string
+	switch n := count.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s = fmt.Sprint(n)
+	case float32:
+		s = strconv.FormatFloat(float64(n), 'f', -1, 32)
+	case float64:
+		s = strconv.FormatFloat(n, 'f', -1, 64)
+	case string:
+		s = strings.TrimSpace(n)
+	default:
+		return 0, 0, 0, 0, 0, false
+	}
+
+	s = strings.TrimLeft(s, "+-")
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	// Operands may be passed modulo 10,000,000, so keep the last 7 digits
+	if len(intPart) > 7 {
+		intPart = intPart[len(intPart)-7:]
+	}
+	trimmed := strings.TrimRight(fracPart, "0")
+	if len(fracPart) > 7 {
+		fracPart = fracPart[:7]
+		trimmed = strings.TrimRight(fracPart, "0")
+	}
+
+	var err error
+	if i, err = strconv.Atoi(intPart); err != nil {
+		return 0, 0, 0, 0, 0, false
+	}
+	if fracPart != "" {
+		if f, err = strconv.Atoi(fracPart); err != nil {
+			return 0, 0, 0, 0, 0, false
+		}
+	}
+	if trimmed != "" {
+		t, _ = strconv.Atoi(trimmed)
+	}
+
+	return i, len(fracPart), len(trimmed), f, t, true
+}
+
+// TransPlural translates a message code like Trans, choosing the plural
+// form of the message that matches count under the CLDR plural rules of
+// the language that serves the message.
+//
+// Plural forms are defined in language files as an object of plural
+// categories (zero, one, two, few, many, other) instead of a string.
+// If the exact category is missing the "other" form is used, and a plain
+// string message is used for every count. Trans, TransMap and responses
+// without a count use the "other" form.
+//
+// Parameters:
+//   - lang: The language code to use for translation
+//   - code: The message code to translate
+//   - count: The quantity selecting the plural form, an integer, a floating-point number or a decimal string
+//   - params: Optional parameters to format into the message template
+//
+// Returns:
+//   - string: The translated message, or the original code if no translation is found
+//
+// Example:
+//
+//	// Assuming "1001" maps to {"one": "%s file", "other": "%s files"}
+//	message := manager.TransPlural("en-US", "1001", 3, "3")
+//	// message will be "3 files"
+func (m *Manager) TransPlural(lang string, code string, count interface{}, params ...string) string {
+	msg, _ := m.translate(context.Background(), lang, code, count, params, nil)
+	return msg
+}
+
+// lookupPlural looks up the plural form of a message in a single language.
+//
+// Parameters:
+//   - cat: The catalog to look up
+//   - lang: The language code to look up
+//   - code: The message code
+//   - count: The quantity selecting the plural form
+//
+// Returns:
+//   - string: The message template
+//   - bool: true if the language has the message, false otherwise
+func (m *Manager) lookupPlural(cat *catalog, lang string, code string, count interface{}) (string, bool) {
+	messages, ok := cat.langs[lang]
+	if !ok {
+		return "", false
+	}
+
+	for _, key := range []string{
+		code + pluralSep + pluralCategory(lang, count),
+		code + pluralSep + pluralForms[plural.Other],
+		code,
+	} {
+		if msg, ok := messages[key]; ok {
+			return msg, true
+		}
+	}
+
+	return "", false
+}
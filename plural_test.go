@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		lang  string
+		count interface{}
+		want  string
+	}{
+		{lang: "en-US", count: 1, want: "one"},
+		{lang: "en-US", count: 0, want: "other"},
+		{lang: "en-US", count: "1.0", want: "other"},
+		{lang: "ru-RU", count: 1, want: "one"},
+		{lang: "ru-RU", count: 3, want: "few"},
+		{lang: "ru-RU", count: 5, want: "many"},
+		{lang: "ru-RU", count: 21, want: "one"},
+		{lang: "ru-RU", count: 1.5, want: "other"},
+		{lang: "pl", count: 22, want: "few"},
+		{lang: "pl", count: 12, want: "many"},
+		{lang: "ar", count: 0, want: "zero"},
+		{lang: "ar", count: 2, want: "two"},
+		{lang: "ar", count: 11, want: "many"},
+		{lang: "zh-CN", count: 1, want: "other"},
+		{lang: "en-US", count: "abc", want: "other"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, pluralCategory(tt.lang, tt.count), "%s %v", tt.lang, tt.count)
+	}
+}
+
+func TestTransPlural(t *testing.T) {
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{"1": {"one": "%s file", "other": "%s files"}, "2": "plain %s"}`,
+		"ru-RU.json": `{"1": {"one": "%s файл", "few": "%s файла", "many": "%s файлов", "other": "%s файла"}}`,
+	})
+
+	assert.Equal(t, "1 file", m.TransPlural("en-US", "1", 1, "1"))
+	assert.Equal(t, "2 files", m.TransPlural("en-US", "1", 2, "2"))
+	assert.Equal(t, "21 файл", m.TransPlural("ru-RU", "1", 21, "21"))
+	assert.Equal(t, "3 файла", m.TransPlural("ru-RU", "1", 3, "3"))
+	assert.Equal(t, "5 файлов", m.TransPlural("ru-RU", "1", 5, "5"))
+	// Falls back to en-US and applies its plural rules
+	assert.Equal(t, "1 file", m.TransPlural("fr-FR", "1", 1, "1"))
+	assert.Equal(t, "plain 3", m.TransPlural("ru-RU", "2", 3, "3"))
+	assert.Equal(t, "404", m.TransPlural("en-US", "404", 3))
+	// Without a count, plural messages use their "other" form
+	assert.Equal(t, "2 files", m.Trans("en-US", "1", "2"))
+	assert.Equal(t, "7 файла", m.Trans("ru-RU", "1", "7"))
+	assert.Equal(t, "%s files", m.TransMap("en-US", "1", nil))
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("lang", "ru-RU")
+	res := m.result(c, 1, Data{Params: []string{"5"}, Count: 5, Data: "files"}, nil)
+	assert.Equal(t, "5 файлов", res.Msg)
+}
+
+func TestParseMessagesInvalid(t *testing.T) {
+	_, err := parseMessages(map[string]interface{}{"1": map[string]interface{}{"one": 1.0}}, "", defaultKeySep)
+	assert.Error(t, err)
+
+	_, err = parseMessages(map[string]interface{}{"1": 1.0}, "", defaultKeySep)
+	assert.Error(t, err)
+}
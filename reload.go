@@ -0,0 +1,249 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// fingerprinter is implemented by sources whose changes can be detected
+// by polling, see WithWatch.
+type fingerprinter interface {
+	fingerprint() (string, error)
+}
+
+// WithWatch returns an Option that enables hot reloading of the language
+// files. The language files of directory and file system sources are
+// polled at the given interval, and when a file is added, removed or
+// modified all sources are reloaded (see Reload).
+// Call Close to stop watching.
+//
+// Parameters:
+//   - interval: The interval between checks, 0 disables watching
+//
+// Returns:
+//   - Option: A function that sets the watch interval in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithWatch(2 * time.Second))
+func WithWatch(interval time.Duration) Option {
+	return func(o *option) {
+		o.watchInterval = interval
+	}
+}
+
+// WithReloadHandler returns an Option that sets the handler notified after
+// every reload, whether triggered by Reload or by a file change when
+// watching. err is nil if the new language files are active, otherwise it
+// describes why they were rejected and the previous ones keep serving.
+//
+// Parameters:
+//   - h: The handler to notify
+//
+// Returns:
+//   - Option: A function that sets the reload handler in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithWatch(time.Second), i18n.WithReloadHandler(func(err error) {
+//	    if err != nil {
+//	        log.Printf("language files rejected: %v", err)
+//	    }
+//	}))
+func WithReloadHandler(h func(err error)) Option {
+	return func(o *option) {
+		o.reloadHandler = h
+	}
+}
+
+// Reload re-reads and validates the language files, then atomically swaps
+// them in. If a file cannot be read or parsed, or an ICU message is invalid,
+// the new files are rejected and the previously loaded ones keep serving.
+// Runtime overrides (see Set) are applied over the reloaded messages.
+// The reload handler is notified of the outcome.
+//
+// Returns:
+//   - error: An error if the new language files were rejected, nil otherwise
+//
+// Example:
+//
+//	if err := manager.Reload(); err != nil {
+//	    log.Printf("reload failed: %v", err)
+//	}
+func (m *Manager) Reload() error {
+	err := m.load()
+	m.reloaded(err)
+
+	return err
+}
+
+// reloaded logs the outcome of a reload and notifies the reload handler.
+// It must be called without holding loadMu, so the handler may reload.
+//
+// Parameters:
+//   - err: The error of the reload, nil if it succeeded
+func (m *Manager) reloaded(err error) {
+	if err != nil {
+		m.log(m.ctx, slog.LevelError, logReloadFailed, slog.Any("error", err))
+	} else {
+		m.log(m.ctx, slog.LevelInfo, logReloaded, slog.Int("langs", m.Count()))
+	}
+	if m.opt.reloadHandler != nil {
+		m.opt.reloadHandler(err)
+	}
+}
+
+// Close stops watching and refreshing the messages. The Manager remains
+// usable: it keeps the messages already loaded, and Reload, Set and
+// ImportXLIFF still load the sources.
+//
+// Returns:
+//   - error: Always nil, provided to satisfy io.Closer
+func (m *Manager) Close() error {
+	m.cancel()
+
+	return nil
+}
+
+// load reads, parses and validates the language files, then swaps them in.
+//
+// Returns:
+//   - error: An error if the language files cannot be used, nil otherwise
+func (m *Manager) load() error {
+	m.loadMu.Lock()
+	defer m.loadMu.Unlock()
+
+	return m.loadLocked()
+}
+
+// loadLocked is load for callers already holding loadMu.
+//
+// Returns:
+//   - error: An error if the language files cannot be used, nil otherwise
+func (m *Manager) loadLocked() error {
+	// Load and merge the layers of messages
+	langList, origins, files, err := m.loadSources()
+	if err != nil {
+		return err
+	}
+
+	// Reject any problem of the messages in strict mode
+	if m.opt.strict {
+		if err = m.validate(langList, files); err != nil {
+			return err
+		}
+	}
+	m.overrides.apply(langList, origins)
+
+	// Ensure at least one language file was loaded
+	if len(langList) == 0 {
+		return errors.New("没有找到语言配置文件")
+	}
+
+	// Parse ICU messages up front so syntax errors fail fast
+	var prev map[string]icuMessage
+	if cur := m.catalog.Load(); cur != nil {
+		prev = cur.icu
+	}
+	patterns, err := m.compileMessages(langList, prev)
+	if err != nil {
+		return err
+	}
+
+	m.catalog.Store(&catalog{langs: langList, origins: origins, matcher: newLangMatcher(langList), icu: patterns})
+
+	// Report printf messages whose verbs are malformed or differ between languages
+	if m.opt.formatErrorHandler != nil || m.opt.logger != nil {
+		for _, ferr := range m.CheckFormats() {
+			m.reportFormatError(m.ctx, ferr)
+		}
+	}
+
+	return nil
+}
+
+// watch starts polling the language directory for changes in the background.
+func (m *Manager) watch() {
+	last, _ := m.fingerprint()
+
+	go func() {
+		ticker := time.NewTicker(m.opt.watchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+				current, err := m.fingerprint()
+				if err != nil || current == last {
+					continue
+				}
+				last = current
+				// Failures are reported to the reload handler
+				_ = m.Reload()
+			}
+		}
+	}()
+}
+
+// fingerprint summarizes the language files of the sources that load them.
+//
+// Returns:
+//   - string: The fingerprint of the sources
+//   - error: An error if a directory cannot be walked
+func (m *Manager) fingerprint() (string, error) {
+	var b strings.Builder
+	for _, src := range m.opt.sources {
+		if f, ok := src.(fingerprinter); ok {
+			fp, err := f.fingerprint()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(fp)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// fingerprint summarizes the names, sizes and modification times of the
+// files in a directory, so any change to them changes the fingerprint.
+// Files of file systems without modification times, such as embed.FS,
+// never change.
+//
+// Parameters:
+//   - fsys: The file system holding the directory
+//   - root: The directory to summarize
+//
+// Returns:
+//   - string: The fingerprint of the directory
+//   - error: An error if the directory cannot be walked
+func fingerprint(fsys fs.FS, root string) (string, error) {
+	var b strings.Builder
+	err := fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s|%d|%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+
+	return b.String(), err
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	dir := writeLangDir(t, map[string]string{"en-US.json": `{"1": "one"}`})
+
+	var events []error
+	m, err := New(WithLangDir(dir), WithReloadHandler(func(err error) {
+		events = append(events, err)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "one", m.Trans("en-US", "1"))
+
+	// A valid change is swapped in
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "en-US.json"), []byte(`{"1": "uno"}`), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "zh-CN.json"), []byte(`{"1": "一"}`), 0o644))
+	assert.NoError(t, m.Reload())
+	assert.Equal(t, "uno", m.Trans("en-US", "1"))
+	assert.Equal(t, "一", m.Trans("zh-CN", "1"))
+	assert.Equal(t, 2, m.Count())
+
+	// An invalid file is rejected and the previous catalog keeps serving
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "zh-CN.json"), []byte(`{"1": `), 0o644))
+	assert.Error(t, m.Reload())
+	assert.Equal(t, "一", m.Trans("zh-CN", "1"))
+
+	if assert.Len(t, events, 2) {
+		assert.NoError(t, events[0])
+		assert.Error(t, events[1])
+	}
+}
+
+func TestReloadDuringTrans(t *testing.T) {
+	printf := `{"1": "%s files"}`
+	icu := `{"@@format": "icu", "1": "{0} files"}`
+	dir := writeLangDir(t, map[string]string{"en-US.json": printf})
+
+	m, err := New(WithLangDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reloads swapping the message format never mix two catalogs in a translation
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			content := printf
+			if i%2 == 0 {
+				content = icu
+			}
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "en-US.json"), []byte(content), 0o644))
+			assert.NoError(t, m.Reload())
+		}
+	}()
+
+	for i := 0; i < 500; i++ {
+		assert.Equal(t, "2 files", m.Trans("en-US", "1", "2"))
+	}
+	wg.Wait()
+}
+
+func TestWatch(t *testing.T) {
+	dir := writeLangDir(t, map[string]string{"en-US.json": `{"1": "one"}`})
+
+	reloaded := make(chan error, 1)
+	m, err := New(WithLangDir(dir), WithWatch(10*time.Millisecond), WithReloadHandler(func(err error) {
+		reloaded <- err
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "en-US.json"), []byte(`{"1": "one (updated)"}`), 0o644))
+
+	select {
+	case err = <-reloaded:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("language files were not reloaded")
+	}
+	assert.Equal(t, "one (updated)", m.Trans("en-US", "1"))
+
+	assert.NoError(t, m.Close())
+	assert.NoError(t, m.Close())
+}
@@ -0,0 +1,284 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+type (
+	// LocaleResolver determines the language of a request.
+	// Resolvers are consulted in order and the first one that reports
+	// a language wins, see WithResolvers. A resolver may also implement
+	// Name() string to be counted under that name by the metrics (see
+	// MetricsHandler), otherwise it is counted as "custom".
+	LocaleResolver interface {
+		// Resolve returns the language code for the request and whether
+		// the resolver was able to determine one.
+		Resolve(c *gin.Context, m *Manager) (string, bool)
+	}
+
+	// ResolverFunc adapts an ordinary function to the LocaleResolver interface.
+	ResolverFunc func(c *gin.Context, m *Manager) (string, bool)
+
+	// headerResolver reads the language from a request header
+	headerResolver struct {
+		name string // Header name
+	}
+
+	// acceptLanguageResolver negotiates the Accept-Language header
+	acceptLanguageResolver struct{}
+
+	// queryResolver reads the language from a URL query parameter
+	queryResolver struct {
+		name string // Query parameter name
+	}
+
+	// cookieResolver reads the language from a cookie
+	cookieResolver struct {
+		name string // Cookie name
+	}
+
+	// pathPrefixResolver reads the language from the first URL path segment
+	pathPrefixResolver struct{}
+
+	// contextResolver reads the language from a gin context key
+	contextResolver struct {
+		key string // Context key
+	}
+
+	// userAgentResolver reads the language from a "name=value" User-Agent parameter
+	userAgentResolver struct {
+		param string // Parameter name
+	}
+)
+
+// defaultResolvers returns the resolver chain used when WithResolvers is not set:
+// the "lang" header, the "lang" User-Agent parameter and the Accept-Language header.
+//
+// Returns:
+//   - []LocaleResolver: The default resolver chain
+func defaultResolvers() []LocaleResolver {
+	return []LocaleResolver{
+		HeaderResolver("lang"),
+		UserAgentResolver("lang"),
+		AcceptLanguageResolver(),
+	}
+}
+
+// WithResolvers returns an Option that sets the ordered chain of resolvers
+// used to determine the language of a request. The first resolver that
+// reports a language wins; if none does, the default language is used.
+//
+// Parameters:
+//   - resolvers: The resolvers to consult, in order of priority
+//
+// Returns:
+//   - Option: A function that sets the resolver chain in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithResolvers(
+//	    i18n.QueryResolver("lang"),
+//	    i18n.CookieResolver("lang"),
+//	    i18n.AcceptLanguageResolver(),
+//	))
+func WithResolvers(resolvers ...LocaleResolver) Option {
+	return func(o *option) {
+		o.resolvers = resolvers
+	}
+}
+
+// Resolve calls f(c, m).
+func (f ResolverFunc) Resolve(c *gin.Context, m *Manager) (string, bool) {
+	return f(c, m)
+}
+
+// HeaderResolver returns a LocaleResolver that reads the language from
+// the named request header.
+//
+// Parameters:
+//   - name: The header name, e.g. "lang" or "X-Language"
+//
+// Returns:
+//   - LocaleResolver: The header resolver
+func HeaderResolver(name string) LocaleResolver {
+	return headerResolver{name: name}
+}
+
+// Name returns "header".
+func (headerResolver) Name() string {
+	return "header"
+}
+
+// Resolve implements LocaleResolver.
+func (r headerResolver) Resolve(c *gin.Context, _ *Manager) (string, bool) {
+	lang := c.Request.Header.Get(r.name)
+	return lang, lang != ""
+}
+
+// AcceptLanguageResolver returns a LocaleResolver that negotiates the
+// standard Accept-Language header against the loaded languages, ranking
+// entries by quality and using BCP 47 matching.
+//
+// Returns:
+//   - LocaleResolver: The Accept-Language resolver
+func AcceptLanguageResolver() LocaleResolver {
+	return acceptLanguageResolver{}
+}
+
+// Name returns "accept_language".
+func (acceptLanguageResolver) Name() string {
+	return "accept_language"
+}
+
+// Resolve implements LocaleResolver.
+func (acceptLanguageResolver) Resolve(c *gin.Context, m *Manager) (string, bool) {
+	accept := c.Request.Header.Get("Accept-Language")
+	if accept == "" {
+		return "", false
+	}
+
+	return m.langMatcher().matchAcceptLanguage(accept)
+}
+
+// QueryResolver returns a LocaleResolver that reads the language from the
+// named URL query parameter, e.g. "/users?lang=zh-CN".
+//
+// Parameters:
+//   - name: The query parameter name
+//
+// Returns:
+//   - LocaleResolver: The query resolver
+func QueryResolver(name string) LocaleResolver {
+	return queryResolver{name: name}
+}
+
+// Name returns "query".
+func (queryResolver) Name() string {
+	return "query"
+}
+
+// Resolve implements LocaleResolver.
+func (r queryResolver) Resolve(c *gin.Context, _ *Manager) (string, bool) {
+	lang := c.Query(r.name)
+	return lang, lang != ""
+}
+
+// CookieResolver returns a LocaleResolver that reads the language from
+// the named cookie.
+//
+// Parameters:
+//   - name: The cookie name
+//
+// Returns:
+//   - LocaleResolver: The cookie resolver
+func CookieResolver(name string) LocaleResolver {
+	return cookieResolver{name: name}
+}
+
+// Name returns "cookie".
+func (cookieResolver) Name() string {
+	return "cookie"
+}
+
+// Resolve implements LocaleResolver.
+func (r cookieResolver) Resolve(c *gin.Context, _ *Manager) (string, bool) {
+	lang, err := c.Cookie(r.name)
+	if err != nil {
+		return "", false
+	}
+
+	return lang, lang != ""
+}
+
+// PathPrefixResolver returns a LocaleResolver that reads the language from
+// the first segment of the URL path, e.g. "/zh-CN/users". The segment is
+// only used if it is one of the loaded languages, so ordinary paths such
+// as "/users" are not mistaken for a language.
+//
+// Returns:
+//   - LocaleResolver: The path prefix resolver
+func PathPrefixResolver() LocaleResolver {
+	return pathPrefixResolver{}
+}
+
+// Name returns "path_prefix".
+func (pathPrefixResolver) Name() string {
+	return "path_prefix"
+}
+
+// Resolve implements LocaleResolver.
+func (pathPrefixResolver) Resolve(c *gin.Context, m *Manager) (string, bool) {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(c.Request.URL.Path, "/"), "/")
+	if segment == "" || !m.LangExist(segment) {
+		return "", false
+	}
+
+	return segment, true
+}
+
+// ContextResolver returns a LocaleResolver that reads the language from a
+// string value stored in the gin context by earlier middleware, such as a
+// claim extracted from a JWT or a user profile lookup.
+//
+// Parameters:
+//   - key: The gin context key
+//
+// Returns:
+//   - LocaleResolver: The context resolver
+//
+// Example:
+//
+//	r.Use(func(c *gin.Context) {
+//	    c.Set("user_lang", claims.Lang)
+//	})
+//	i18n.New(i18n.WithResolvers(i18n.ContextResolver("user_lang")))
+func ContextResolver(key string) LocaleResolver {
+	return contextResolver{key: key}
+}
+
+// Name returns "context".
+func (contextResolver) Name() string {
+	return "context"
+}
+
+// Resolve implements LocaleResolver.
+func (r contextResolver) Resolve(c *gin.Context, _ *Manager) (string, bool) {
+	lang := c.GetString(r.key)
+	return lang, lang != ""
+}
+
+// UserAgentResolver returns a LocaleResolver that reads the language from a
+// "name=value" parameter in the User-Agent string, where parameters are
+// separated by ";", e.g. "app/1.0;lang=zh-CN".
+//
+// Parameters:
+//   - param: The User-Agent parameter name
+//
+// Returns:
+//   - LocaleResolver: The User-Agent resolver
+func UserAgentResolver(param string) LocaleResolver {
+	return userAgentResolver{param: param}
+}
+
+// Name returns "user_agent".
+func (userAgentResolver) Name() string {
+	return "user_agent"
+}
+
+// Resolve implements LocaleResolver.
+func (r userAgentResolver) Resolve(c *gin.Context, _ *Manager) (string, bool) {
+	ua := c.Request.UserAgent()
+	for _, param := range strings.Split(ua, ";") {
+		paramList := strings.Split(param, "=")
+		if len(paramList) == 2 && paramList[0] == r.param {
+			return paramList[1], true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolvers(t *testing.T) {
+	profile := ResolverFunc(func(c *gin.Context, m *Manager) (string, bool) {
+		if c.GetHeader("X-User") == "seakee" {
+			return "zh-CN", true
+		}
+		return "", false
+	})
+
+	m, err := New(WithResolvers(
+		QueryResolver("lang"),
+		CookieResolver("lang"),
+		PathPrefixResolver(),
+		ContextResolver("claim_lang"),
+		profile,
+		HeaderResolver("X-Lang"),
+		AcceptLanguageResolver(),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		setup func(c *gin.Context)
+		want  string
+	}{
+		{name: "query", setup: func(c *gin.Context) {
+			c.Request = httptest.NewRequest("GET", "/users?lang=zh-CN", nil)
+		}, want: "zh-CN"},
+		{name: "cookie", setup: func(c *gin.Context) {
+			c.Request.AddCookie(&http.Cookie{Name: "lang", Value: "zh-CN"})
+		}, want: "zh-CN"},
+		{name: "path prefix", setup: func(c *gin.Context) {
+			c.Request = httptest.NewRequest("GET", "/zh-CN/users", nil)
+		}, want: "zh-CN"},
+		{name: "unknown path prefix", setup: func(c *gin.Context) {
+			c.Request = httptest.NewRequest("GET", "/fr/users", nil)
+		}, want: "en-US"},
+		{name: "context key", setup: func(c *gin.Context) {
+			c.Set("claim_lang", "zh-CN")
+		}, want: "zh-CN"},
+		{name: "func", setup: func(c *gin.Context) {
+			c.Request.Header.Set("X-User", "seakee")
+		}, want: "zh-CN"},
+		{name: "header", setup: func(c *gin.Context) {
+			c.Request.Header.Set("X-Lang", "zh-CN")
+		}, want: "zh-CN"},
+		{name: "accept language", setup: func(c *gin.Context) {
+			c.Request.Header.Set("Accept-Language", "zh-TW,zh;q=0.9")
+		}, want: "zh-CN"},
+		{name: "order", setup: func(c *gin.Context) {
+			c.Request = httptest.NewRequest("GET", "/users?lang=en-US", nil)
+			c.Request.Header.Set("X-Lang", "zh-CN")
+		}, want: "en-US"},
+		{name: "user agent not configured", setup: func(c *gin.Context) {
+			c.Request.Header.Set("User-Agent", "app/1.0;lang=zh-CN")
+		}, want: "en-US"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			tt.setup(c)
+
+			assert.Equal(t, tt.want, m.lang(c))
+		})
+	}
+}
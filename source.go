@@ -0,0 +1,251 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type (
+	// Source provides a layer of messages. Sources are loaded in order and
+	// later sources override the messages of earlier ones per key, see
+	// WithSources.
+	Source interface {
+		// Name identifies the source, as reported by Origin.
+		Name() string
+		// Load returns the messages of the source, keyed by language code
+		// and then by message code.
+		Load(m *Manager) (map[string]map[string]string, error)
+	}
+
+	// fsSource loads the language files of a directory of a file system
+	fsSource struct {
+		name string // Source name
+		fsys fs.FS  // File system holding the language files
+		root string // Directory of the language files within fsys
+		dir  string // Directory on disk the file system is rooted at, "" if not on disk
+	}
+
+	// mapSource provides in-memory messages
+	mapSource struct {
+		name     string                       // Source name
+		messages map[string]map[string]string // Messages keyed by language and code
+	}
+)
+
+// WithSources returns an Option that sets the ordered layers of messages.
+// Each source is loaded in order, and a message of a later source replaces
+// the message with the same language and code of earlier sources, so a
+// service can ship a base catalog and override a handful of messages. It
+// replaces WithLangDir and WithFS.
+//
+// Parameters:
+//   - sources: The sources, from the lowest to the highest precedence
+//
+// Returns:
+//   - Option: A function that sets the sources in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithSources(
+//	    i18n.FSSource(base.LangFS, "lang"),
+//	    i18n.DirSource("./lang"),
+//	    i18n.MapSource("overrides", map[string]map[string]string{
+//	        "en-US": {"500": "Something went wrong"},
+//	    }),
+//	))
+func WithSources(sources ...Source) Option {
+	return func(o *option) {
+		o.sources = sources
+	}
+}
+
+// DirSource returns a Source that loads the language files of a directory
+// on disk. It is named after the directory.
+//
+// Parameters:
+//   - dir: The directory of the language files
+//
+// Returns:
+//   - Source: The directory source
+func DirSource(dir string) Source {
+	return &fsSource{name: dir, fsys: os.DirFS(dir), root: ".", dir: dir}
+}
+
+// FSSource returns a Source that loads the language files of a directory
+// of a file system, such as an embed.FS. It is named "fs:" followed by the
+// directory.
+//
+// Parameters:
+//   - fsys: The file system holding the language files
+//   - root: The directory of the language files within fsys, "." for its root
+//
+// Returns:
+//   - Source: The file system source
+func FSSource(fsys fs.FS, root string) Source {
+	return &fsSource{name: "fs:" + root, fsys: fsys, root: root}
+}
+
+// MapSource returns a Source providing in-memory messages. The messages
+// are copied, so changing the map afterwards has no effect.
+//
+// Parameters:
+//   - name: The name of the source
+//   - messages: The messages keyed by language code and then by message code
+//
+// Returns:
+//   - Source: The in-memory source
+func MapSource(name string, messages map[string]map[string]string) Source {
+	return &mapSource{name: name, messages: copyLangList(messages)}
+}
+
+// Name implements Source.
+func (s *fsSource) Name() string {
+	return s.name
+}
+
+// Load implements Source.
+func (s *fsSource) Load(m *Manager) (map[string]map[string]string, error) {
+	langList, _, err := s.loadFiles(m)
+	return langList, err
+}
+
+// loadFiles loads the language files of the source, remembering the file
+// each message came from.
+//
+// Parameters:
+//   - m: The Manager loading the source
+//
+// Returns:
+//   - map[string]map[string]string: The messages keyed by language and code
+//   - map[string]map[string]string: The path of the file defining each message, keyed the same way
+//   - error: An error if reading or parsing files fails
+func (s *fsSource) loadFiles(m *Manager) (map[string]map[string]string, map[string]map[string]string, error) {
+	langList, files, err := loadLangFiles(s.fsys, s.root, m.opt.keySep, m.opt.namespaces)
+	if err != nil || s.dir == "" {
+		return langList, files, err
+	}
+
+	// Report paths on disk rather than within the directory
+	for _, paths := range files {
+		for code, path := range paths {
+			paths[code] = filepath.Join(s.dir, path)
+		}
+	}
+
+	return langList, files, nil
+}
+
+// fingerprint implements fingerprinter.
+func (s *fsSource) fingerprint() (string, error) {
+	return fingerprint(s.fsys, s.root)
+}
+
+// Name implements Source.
+func (s *mapSource) Name() string {
+	return s.name
+}
+
+// Load implements Source.
+func (s *mapSource) Load(*Manager) (map[string]map[string]string, error) {
+	return copyLangList(s.messages), nil
+}
+
+// loadSources loads and merges the configured sources in order of
+// precedence, remembering the source and the file each message came from.
+// A source defining a message, plain or in plural forms, replaces every
+// form of it defined by earlier sources.
+//
+// Returns:
+//   - map[string]map[string]string: The merged messages keyed by language and code
+//   - map[string]map[string]string: The name of the source of each message, keyed the same way
+//   - map[string]map[string]string: The file defining each message, or the source name for
+//     messages not loaded from language files, keyed the same way
+//   - error: An error if a source cannot be loaded
+func (m *Manager) loadSources() (map[string]map[string]string, map[string]map[string]string, map[string]map[string]string, error) {
+	langList := make(map[string]map[string]string)
+	origins := make(map[string]map[string]string)
+	files := make(map[string]map[string]string)
+	for _, src := range m.opt.sources {
+		var messages, paths map[string]map[string]string
+		var err error
+		if fsrc, ok := src.(*fsSource); ok {
+			messages, paths, err = fsrc.loadFiles(m)
+		} else {
+			messages, err = src.Load(m)
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%s: %w", src.Name(), err)
+		}
+
+		for lang, msgs := range messages {
+			if langList[lang] == nil {
+				langList[lang] = make(map[string]string, len(msgs))
+				origins[lang] = make(map[string]string, len(msgs))
+				files[lang] = make(map[string]string, len(msgs))
+			}
+			// A layer defining a code replaces all its forms of lower layers
+			for key := range msgs {
+				code, _, _ := cutPluralCategory(key)
+				for _, k := range pluralForms {
+					k = code + pluralSep + k
+					delete(langList[lang], k)
+					delete(origins[lang], k)
+					delete(files[lang], k)
+				}
+				delete(langList[lang], code)
+				delete(origins[lang], code)
+				delete(files[lang], code)
+			}
+			for code, msg := range msgs {
+				langList[lang][code] = msg
+				origins[lang][code] = src.Name()
+				files[lang][code] = src.Name()
+				if path, ok := paths[lang][code]; ok {
+					files[lang][code] = path
+				}
+			}
+		}
+	}
+
+	return langList, origins, files, nil
+}
+
+// diskDir returns the directory of the highest precedence source that
+// loads language files from disk, which is where messages are written.
+//
+// Returns:
+//   - string: The directory, "" if no source loads from disk
+func (m *Manager) diskDir() string {
+	for i := len(m.opt.sources) - 1; i >= 0; i-- {
+		if s, ok := m.opt.sources[i].(*fsSource); ok && s.dir != "" {
+			return s.dir
+		}
+	}
+
+	return ""
+}
+
+// copyLangList returns a deep copy of messages keyed by language and code.
+//
+// Parameters:
+//   - langs: The messages to copy
+//
+// Returns:
+//   - map[string]map[string]string: The copy
+func copyLangList(langs map[string]map[string]string) map[string]map[string]string {
+	cp := make(map[string]map[string]string, len(langs))
+	for lang, messages := range langs {
+		cp[lang] = make(map[string]string, len(messages))
+		for code, msg := range messages {
+			cp[lang][code] = msg
+		}
+	}
+
+	return cp
+}
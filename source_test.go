@@ -0,0 +1,91 @@
+package i18n
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// errSource is a Source that always fails to load.
+type errSource struct{}
+
+func (errSource) Name() string { return "broken" }
+
+func (errSource) Load(*Manager) (map[string]map[string]string, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestWithSources(t *testing.T) {
+	base := fstest.MapFS{
+		"lang/en-US.json": {Data: []byte(`{"0": "ok", "500": "fail", "1001": {"one": "%s item", "other": "%s items"}, "1002": {"one": "%s box", "other": "%s boxes"}, "1003": "%s crates"}`)},
+		"lang/zh-CN.json": {Data: []byte(`{"0": "成功"}`)},
+	}
+	dir := writeLangDir(t, map[string]string{"en-US.json": `{"500": "Something went wrong", "1003": {"one": "%s crate", "other": "%s crates!"}}`})
+	overrides := map[string]map[string]string{"en-US": {"0": "all good", "1002": "%s parcels"}, "fr": {"0": "d'accord"}}
+
+	m, err := New(WithSources(FSSource(base, "lang"), DirSource(dir), MapSource("overrides", overrides)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// MapSource copies its messages
+	overrides["en-US"]["0"] = "changed"
+
+	assert.ElementsMatch(t, []string{"en-US", "zh-CN", "fr"}, m.Lang())
+	assert.Equal(t, "all good", m.Trans("en-US", "0"))
+	assert.Equal(t, "Something went wrong", m.Trans("en-US", "500"))
+	assert.Equal(t, "2 items", m.TransPlural("en-US", "1001", 2, "2"))
+	// A code defined by a layer replaces all its forms of lower layers
+	assert.Equal(t, "1 parcels", m.TransPlural("en-US", "1002", 1, "1"))
+	assert.Equal(t, "3 crates!", m.Trans("en-US", "1003", "3"))
+	assert.Equal(t, "1 crate", m.TransPlural("en-US", "1003", 1, "1"))
+	assert.Equal(t, "成功", m.Trans("zh-CN", "0"))
+	assert.Equal(t, "d'accord", m.Trans("fr", "0"))
+
+	for _, tt := range []struct{ lang, code, origin string }{
+		{"en-US", "0", "overrides"},
+		{"en-US", "500", dir},
+		{"en-US", "1001", "fs:lang"},
+		{"en-US", "1001.one", "fs:lang"},
+		{"en-US", "1002", "overrides"},
+		{"en-US", "1003", dir},
+		{"zh-CN", "0", "fs:lang"},
+	} {
+		origin, ok := m.Origin(tt.lang, tt.code)
+		assert.True(t, ok, tt.code)
+		assert.Equal(t, tt.origin, origin, tt.code)
+	}
+	_, ok := m.Origin("en-US", "404")
+	assert.False(t, ok)
+	_, ok = m.Origin("de", "0")
+	assert.False(t, ok)
+
+	// The highest precedence directory is written by imports
+	err = m.ImportXLIFF(strings.NewReader(`<xliff version="1.2"><file target-language="en-US"><body>
+		<trans-unit id="404"><source>x</source><target>Not found</target></trans-unit>
+	</body></file></xliff>`))
+	if assert.NoError(t, err) {
+		origin, _ := m.Origin("en-US", "404")
+		assert.Equal(t, dir, origin)
+		data, _ := os.ReadFile(filepath.Join(dir, "en-US.json"))
+		assert.Contains(t, string(data), "Not found")
+	}
+}
+
+func TestWithSourcesErrors(t *testing.T) {
+	_, err := New(WithSources(DirSource("./lang"), errSource{}))
+	assert.EqualError(t, err, "broken: unavailable")
+
+	_, err = New(WithSources())
+	assert.Error(t, err)
+
+	// Without a directory on disk nothing can be written
+	m, err := New(WithSources(MapSource("memory", map[string]map[string]string{"en-US": {"0": "ok"}})))
+	if assert.NoError(t, err) {
+		assert.Error(t, m.ImportXLIFF(strings.NewReader(`<xliff version="1.2"><file target-language="en-US"/></xliff>`)))
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import "net/http"
+
+type (
+	// StatusFunc maps a response code to an HTTP status code, and reports
+	// whether it did, see WithStatusFunc.
+	StatusFunc func(code int) (int, bool)
+
+	// StatusRange maps a range of response codes to an HTTP status code,
+	// see WithStatusRanges.
+	StatusRange struct {
+		From   int // First response code of the range
+		To     int // Last response code of the range, inclusive
+		Status int // HTTP status code of the range
+	}
+)
+
+// WithStatusCodes returns an Option that maps response codes to HTTP
+// status codes, see HTTPStatus.
+//
+// Parameters:
+//   - statuses: A map of response codes to their HTTP status codes
+//
+// Returns:
+//   - Option: A function that sets the status table in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithStatusCodes(map[int]int{
+//	    -1:   http.StatusServiceUnavailable,
+//	    1001: http.StatusUnauthorized,
+//	}))
+func WithStatusCodes(statuses map[int]int) Option {
+	return func(o *option) {
+		o.statusCodes = statuses
+	}
+}
+
+// WithStatusRanges returns an Option that maps ranges of response codes
+// to HTTP status codes, see HTTPStatus. The first range containing a code
+// applies.
+//
+// Parameters:
+//   - ranges: The ranges, in order of priority
+//
+// Returns:
+//   - Option: A function that sets the status ranges in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithStatusRanges(
+//	    i18n.StatusRange{From: 10000, To: 19999, Status: http.StatusBadRequest},
+//	    i18n.StatusRange{From: 20000, To: 29999, Status: http.StatusInternalServerError},
+//	))
+func WithStatusRanges(ranges ...StatusRange) Option {
+	return func(o *option) {
+		o.statusRanges = ranges
+	}
+}
+
+// WithStatusFunc returns an Option that sets a function mapping response
+// codes to HTTP status codes, consulted before the status table and
+// ranges, see HTTPStatus.
+//
+// Parameters:
+//   - f: The mapping function
+//
+// Returns:
+//   - Option: A function that sets the status function in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithStatusFunc(func(code int) (int, bool) {
+//	    if code/1000 == 4 {
+//	        return http.StatusForbidden, true
+//	    }
+//	    return 0, false
+//	}))
+func WithStatusFunc(f StatusFunc) Option {
+	return func(o *option) {
+		o.statusFunc = f
+	}
+}
+
+// WithAlwaysOK returns an Option that makes every response helper answer
+// with HTTP 200 whatever the response code, as before status mapping was
+// introduced.
+//
+// Parameters:
+//   - enabled: Whether to always answer with HTTP 200
+//
+// Returns:
+//   - Option: A function that disables status mapping in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithAlwaysOK(true))
+func WithAlwaysOK(enabled bool) Option {
+	return func(o *option) {
+		o.alwaysOK = enabled
+	}
+}
+
+// HTTPStatus returns the HTTP status code the response helpers (JSON, XML,
+// YAML, ...) answer with for a response code. It is, in order: the status
+// given by the status function (see WithStatusFunc), the status table (see
+// WithStatusCodes), the first status range containing the code (see
+// WithStatusRanges), the code itself if it is an HTTP error status
+// (400-599), and 200 otherwise. It is always 200 with WithAlwaysOK.
+//
+// Parameters:
+//   - code: The response code
+//
+// Returns:
+//   - int: The HTTP status code
+//
+// Example:
+//
+//	c.JSON(manager.HTTPStatus(code), payload)
+func (m *Manager) HTTPStatus(code int) int {
+	if m.opt.alwaysOK {
+		return http.StatusOK
+	}
+
+	if m.opt.statusFunc != nil {
+		if status, ok := m.opt.statusFunc(code); ok {
+			return status
+		}
+	}
+	if status, ok := m.opt.statusCodes[code]; ok {
+		return status
+	}
+	for _, r := range m.opt.statusRanges {
+		if code >= r.From && code <= r.To {
+			return r.Status
+		}
+	}
+
+	if code >= http.StatusBadRequest && code <= 599 {
+		return code
+	}
+
+	return http.StatusOK
+}
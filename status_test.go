@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	m, err := New(WithLangDir("./lang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for code, status := range map[int]int{-1: 200, 0: 200, 204: 200, 302: 200, 400: 400, 404: 404, 503: 503, 600: 200, 1000: 200} {
+		assert.Equal(t, status, m.HTTPStatus(code), "code %d", code)
+	}
+
+	m, err = New(
+		WithLangDir("./lang"),
+		WithStatusFunc(func(code int) (int, bool) {
+			return http.StatusTeapot, code == 10001
+		}),
+		WithStatusCodes(map[int]int{-1: http.StatusServiceUnavailable, 10001: http.StatusBadRequest, 500: http.StatusOK}),
+		WithStatusRanges(
+			StatusRange{From: 10000, To: 19999, Status: http.StatusBadRequest},
+			StatusRange{From: 15000, To: 29999, Status: http.StatusInternalServerError},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for code, status := range map[int]int{
+		10001: http.StatusTeapot,             // function first
+		-1:    http.StatusServiceUnavailable, // then the table
+		500:   http.StatusOK,
+		15000: http.StatusBadRequest, // then the first range containing the code
+		20000: http.StatusInternalServerError,
+		404:   http.StatusNotFound, // then HTTP error statuses
+		0:     http.StatusOK,
+	} {
+		assert.Equal(t, status, m.HTTPStatus(code), "code %d", code)
+	}
+
+	m, err = New(WithLangDir("./lang"), WithAlwaysOK(true), WithStatusCodes(map[int]int{-1: http.StatusServiceUnavailable}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusOK, m.HTTPStatus(-1))
+	assert.Equal(t, http.StatusOK, m.HTTPStatus(500))
+}
+
+func TestResponseStatus(t *testing.T) {
+	m, err := New(WithLangDir("./lang"), WithStatusCodes(map[int]int{1000: http.StatusUnauthorized}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.GET("/json", func(c *gin.Context) { m.JSON(c, 500, nil, nil) })
+	r.GET("/jsonp", func(c *gin.Context) { m.JSONP(c, 1000, nil, nil) })
+	r.GET("/ascii", func(c *gin.Context) { m.AsciiJSON(c, 400, nil, nil) })
+	r.GET("/pure", func(c *gin.Context) { m.PureJSON(c, 0, nil, nil) })
+	r.GET("/xml", func(c *gin.Context) { m.XML(c, 500, nil, nil) })
+	r.GET("/yaml", func(c *gin.Context) { m.YAML(c, 1000, nil, nil) })
+
+	for path, status := range map[string]int{
+		"/json":  http.StatusInternalServerError,
+		"/jsonp": http.StatusUnauthorized,
+		"/ascii": http.StatusBadRequest,
+		"/pure":  http.StatusOK,
+		"/xml":   http.StatusInternalServerError,
+		"/yaml":  http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, status, w.Code, path)
+		assert.NotEmpty(t, w.Body.String(), path)
+	}
+}
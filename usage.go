@@ -0,0 +1,183 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// KeyUsage is how often a message was served in a language, see Usage.
+	KeyUsage struct {
+		Lang  string    `json:"lang"`  // Language that served the message
+		Key   string    `json:"key"`   // Message code
+		Count int64     `json:"count"` // Number of times the message was served
+		Last  time.Time `json:"last"`  // When the message was last served
+	}
+
+	// UsageReport compares the messages served with the loaded catalog,
+	// see Usage.
+	UsageReport struct {
+		Since  time.Time  `json:"since"`          // When tracking started or was reset
+		Used   []KeyUsage `json:"used,omitempty"` // Messages served, sorted by language and code
+		Unused []string   `json:"unused"`         // Codes of the catalog never served in any language, sorted
+	}
+
+	// usageTracker records the messages served
+	usageTracker struct {
+		mu    sync.RWMutex // Guards since, and keeps recording out while resetting
+		since time.Time    // When tracking started or was reset
+		keys  sync.Map     // *usageEntry keyed by serving language and code
+	}
+
+	// usageEntry counts the uses of a message
+	usageEntry struct {
+		count atomic.Int64 // Number of times the message was served
+		last  atomic.Int64 // When the message was last served, in Unix nanoseconds
+	}
+)
+
+// WithUsageTracking returns an Option that records which messages are
+// served, per language, with counts and the time they were last served,
+// so codes nobody uses can be found and pruned (see Usage).
+//
+// Parameters:
+//   - enabled: Whether to track usage
+//
+// Returns:
+//   - Option: A function that sets usage tracking in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithUsageTracking(true))
+func WithUsageTracking(enabled bool) Option {
+	return func(o *option) {
+		o.usageTracking = enabled
+	}
+}
+
+// Usage reports the messages served since tracking started or was reset,
+// and the codes of the loaded catalog never served in any language. Plural
+// forms are reported under their message code, and metadata keys and log
+// messages (see WithLogLang) are left out. The report is empty if usage
+// tracking is disabled.
+//
+// Returns:
+//   - UsageReport: The usage report
+//
+// Example:
+//
+//	for _, code := range manager.Usage().Unused {
+//	    fmt.Println("unused:", code)
+//	}
+func (m *Manager) Usage() UsageReport {
+	if !m.opt.usageTracking {
+		return UsageReport{}
+	}
+
+	m.usage.mu.RLock()
+	report := UsageReport{Since: m.usage.since, Unused: []string{}}
+	used := make(map[string]bool)
+	m.usage.keys.Range(func(key, value interface{}) bool {
+		id, e := key.([2]string), value.(*usageEntry)
+		report.Used = append(report.Used, KeyUsage{Lang: id[0], Key: id[1], Count: e.count.Load(), Last: time.Unix(0, e.last.Load())})
+		used[id[1]] = true
+		return true
+	})
+	m.usage.mu.RUnlock()
+
+	sort.Slice(report.Used, func(i, j int) bool {
+		if report.Used[i].Lang != report.Used[j].Lang {
+			return report.Used[i].Lang < report.Used[j].Lang
+		}
+		return report.Used[i].Key < report.Used[j].Key
+	})
+
+	for _, messages := range m.langList() {
+		for key := range messages {
+			code, _, _ := cutPluralCategory(key)
+			if isMetaKey(key) || strings.HasPrefix(code, logKeyPrefix) || used[code] {
+				continue
+			}
+			used[code] = true
+			report.Unused = append(report.Unused, code)
+		}
+	}
+	sort.Strings(report.Unused)
+
+	return report
+}
+
+// ResetUsage clears the recorded usage, e.g. after a deployment.
+//
+// Example:
+//
+//	manager.ResetUsage()
+func (m *Manager) ResetUsage() {
+	m.usage.mu.Lock()
+	defer m.usage.mu.Unlock()
+
+	m.usage.since = time.Now()
+	m.usage.keys.Range(func(key, _ interface{}) bool {
+		m.usage.keys.Delete(key)
+		return true
+	})
+}
+
+// UsageHandler returns an http.Handler serving the usage report as JSON,
+// see Usage. Only the unused codes are listed unless the "used" query
+// parameter is set.
+//
+// Returns:
+//   - http.Handler: The usage handler
+//
+// Example:
+//
+//	r.GET("/debug/i18n/usage", gin.WrapH(manager.UsageHandler()))
+//	// GET /debug/i18n/usage        -> {"since": "...", "unused": ["1234", ...]}
+//	// GET /debug/i18n/usage?used=1 -> also lists the messages served
+func (m *Manager) UsageHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := m.Usage()
+		if r.URL.Query().Get("used") == "" {
+			report.Used = nil
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	})
+}
+
+// recordUsage records that a message was served, if usage tracking is
+// enabled.
+//
+// Parameters:
+//   - lang: The language that served the message
+//   - code: The message code
+func (m *Manager) recordUsage(lang string, code string) {
+	if !m.opt.usageTracking {
+		return
+	}
+
+	m.usage.mu.RLock()
+	defer m.usage.mu.RUnlock()
+
+	id := [2]string{lang, code}
+	v, ok := m.usage.keys.Load(id)
+	if !ok {
+		v, _ = m.usage.keys.LoadOrStore(id, new(usageEntry))
+	}
+	e := v.(*usageEntry)
+	e.count.Add(1)
+	e.last.Store(time.Now().UnixNano())
+}
@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUsage(t *testing.T) {
+	m, err := New(WithLangDir("./lang"), WithDefaultLang("en-US"), WithUsageTracking(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+
+	m.Trans("zh-CN", "0")
+	m.Trans("zh-CN", "0")
+	m.Trans("fr", "500")
+	m.TransPlural("zh-CN", "1001", 2, "2")
+	m.Trans("en-US", "1234")
+
+	report := m.Usage()
+	assert.False(t, report.Since.After(start))
+	if assert.Len(t, report.Used, 3) {
+		assert.Equal(t, KeyUsage{Lang: "en-US", Key: "500", Count: 1, Last: report.Used[0].Last}, report.Used[0])
+		assert.Equal(t, KeyUsage{Lang: "zh-CN", Key: "0", Count: 2, Last: report.Used[1].Last}, report.Used[1])
+		assert.Equal(t, "1001", report.Used[2].Key)
+		assert.False(t, report.Used[1].Last.Before(start))
+	}
+	assert.Equal(t, []string{"-1", "1000", "400"}, report.Unused)
+
+	// The handler lists the unused codes
+	w := httptest.NewRecorder()
+	m.UsageHandler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	var got UsageReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, []string{"-1", "1000", "400"}, got.Unused)
+	assert.Nil(t, got.Used)
+
+	w = httptest.NewRecorder()
+	m.UsageHandler().ServeHTTP(w, httptest.NewRequest("GET", "/?used=1", nil))
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got.Used, 3)
+
+	m.ResetUsage()
+	report = m.Usage()
+	assert.Empty(t, report.Used)
+	assert.Len(t, report.Unused, 6)
+	assert.True(t, report.Since.After(start))
+}
+
+func TestUsageDisabled(t *testing.T) {
+	m, err := New(WithLangDir("./lang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Trans("zh-CN", "0")
+	assert.Equal(t, UsageReport{}, m.Usage())
+}
@@ -0,0 +1,182 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrDefaultLangMissing reports that the default language has no messages
+	ErrDefaultLangMissing = errors.New("default language not loaded")
+	// ErrKeyMissing reports a key of the default language missing in another language
+	ErrKeyMissing = errors.New("missing translation of a default language key")
+	// ErrEmptyMessage reports a message that is empty or only whitespace
+	ErrEmptyMessage = errors.New("empty message")
+)
+
+type (
+	// CatalogError lists every problem found in the messages by strict
+	// validation, see WithStrict.
+	CatalogError struct {
+		Problems []*CatalogProblem // Problems found, sorted by language and key
+	}
+
+	// CatalogProblem describes a problem of a message, or of a whole
+	// language.
+	CatalogProblem struct {
+		Lang string // Language of the problem
+		Key  string // Message code, "" for a problem of the whole language
+		File string // File defining the message, or the source name for messages not loaded from files; for a missing key, the file defining it in the default language
+		Err  error  // ErrDefaultLangMissing, ErrKeyMissing, ErrEmptyMessage or a *FormatError
+	}
+)
+
+// WithStrict returns an Option that validates the messages of the sources
+// on every load, so New fails, and reloads are rejected, when:
+//   - the default language has no messages;
+//   - a key of the default language is missing in another language;
+//   - the printf verbs of a message are malformed, or differ from the same
+//     key in the default language;
+//   - a message is empty.
+//
+// The returned error is a *CatalogError listing every problem. Runtime
+// overrides (see Set) are validated when they are set instead.
+//
+// Parameters:
+//   - enabled: Whether to validate the messages
+//
+// Returns:
+//   - Option: A function that sets strict validation in the options
+//
+// Example:
+//
+//	manager, err := i18n.New(i18n.WithStrict(true))
+//	var cerr *i18n.CatalogError
+//	if errors.As(err, &cerr) {
+//	    for _, p := range cerr.Problems {
+//	        log.Println(p)
+//	    }
+//	}
+func WithStrict(enabled bool) Option {
+	return func(o *option) {
+		o.strict = enabled
+	}
+}
+
+// Error implements the error interface.
+func (e *CatalogError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "i18n: %d catalog problems:", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n\t")
+		b.WriteString(p.Error())
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the problems, so errors.Is and errors.As match any of them.
+func (e *CatalogError) Unwrap() []error {
+	errs := make([]error, len(e.Problems))
+	for i, p := range e.Problems {
+		errs[i] = p
+	}
+
+	return errs
+}
+
+// Error implements the error interface.
+func (p *CatalogProblem) Error() string {
+	var b strings.Builder
+	if p.File != "" {
+		b.WriteString(p.File + ": ")
+	}
+	b.WriteString(p.Lang + ": ")
+	if p.Key != "" {
+		fmt.Fprintf(&b, "key %q: ", p.Key)
+	}
+
+	var ferr *FormatError
+	if errors.As(p.Err, &ferr) {
+		b.WriteString(ferr.detail())
+	} else {
+		b.WriteString(p.Err.Error())
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the problem.
+func (p *CatalogProblem) Unwrap() error {
+	return p.Err
+}
+
+// validate checks the messages of the sources for strict mode.
+//
+// Parameters:
+//   - langList: The messages keyed by language and code
+//   - files: The file defining each message, keyed the same way
+//
+// Returns:
+//   - error: A *CatalogError listing every problem, nil if there are none
+func (m *Manager) validate(langList map[string]map[string]string, files map[string]map[string]string) error {
+	var problems []*CatalogProblem
+	ref := m.DefaultLang()
+
+	refMessages, ok := langList[ref]
+	if !ok {
+		problems = append(problems, &CatalogProblem{Lang: ref, Err: ErrDefaultLangMissing})
+	}
+
+	for lang, messages := range langList {
+		// Codes of the language, with the plural forms of a message as one
+		codes := make(map[string]bool, len(messages))
+		for key, msg := range messages {
+			if isMetaKey(key) {
+				continue
+			}
+			code, _, _ := cutPluralCategory(key)
+			codes[code] = true
+
+			if strings.TrimSpace(msg) == "" {
+				problems = append(problems, &CatalogProblem{Lang: lang, Key: key, File: files[lang][key], Err: ErrEmptyMessage})
+			}
+		}
+
+		if lang == ref {
+			continue
+		}
+		for key := range refMessages {
+			if isMetaKey(key) {
+				continue
+			}
+			if code, _, _ := cutPluralCategory(key); !codes[code] {
+				codes[code] = true
+				problems = append(problems, &CatalogProblem{Lang: lang, Key: code, File: files[ref][key], Err: ErrKeyMissing})
+			}
+		}
+	}
+
+	for _, ferr := range m.checkFormats(langList) {
+		problems = append(problems, &CatalogProblem{Lang: ferr.Lang, Key: ferr.Key, File: files[ferr.Lang][ferr.Key], Err: ferr})
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Lang != problems[j].Lang {
+			return problems[i].Lang < problems[j].Lang
+		}
+		return problems[i].Key < problems[j].Key
+	})
+
+	return &CatalogError{Problems: problems}
+}
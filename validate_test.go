@@ -0,0 +1,96 @@
+package i18n
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithStrict(t *testing.T) {
+	m, err := New(WithLangDir("./lang"), WithStrict(true))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "你有2条新消息", m.TransPlural("zh-CN", "1001", 2, "2"))
+	}
+
+	dir := writeLangDir(t, map[string]string{
+		"en-US.json":      `{"0": "ok", "1000": "Hello,%s!", "1001": {"one": "%s item", "other": "%s items"}, "@1000": "Greeting"}`,
+		"zh-CN/base.json": `{"0": "成功", "1000": "你好!", "1001": {"other": "%s个"}}`,
+		"fr.json":         `{"0": " ", "1000": "Bonjour %s %"}`,
+	})
+	_, err = New(WithLangDir(dir), WithStrict(true))
+
+	var cerr *CatalogError
+	if !assert.True(t, errors.As(err, &cerr)) {
+		t.FailNow()
+	}
+	enFile := filepath.Join(dir, "en-US.json")
+	frFile := filepath.Join(dir, "fr.json")
+	zhFile := filepath.Join(dir, "zh-CN", "base.json")
+	assert.Equal(t, []*CatalogProblem{
+		{Lang: "fr", Key: "0", File: frFile, Err: ErrEmptyMessage},
+		{Lang: "fr", Key: "1000", File: frFile, Err: cerr.Problems[1].Err},
+		{Lang: "fr", Key: "1001", File: enFile, Err: ErrKeyMissing},
+		{Lang: "zh-CN", Key: "1000", File: zhFile, Err: cerr.Problems[3].Err},
+	}, cerr.Problems)
+
+	assert.True(t, errors.Is(err, ErrEmptyMessage))
+	assert.True(t, errors.Is(err, ErrKeyMissing))
+	assert.False(t, errors.Is(err, ErrDefaultLangMissing))
+	var ferr *FormatError
+	if assert.True(t, errors.As(cerr.Problems[3].Err, &ferr)) {
+		assert.Equal(t, "en-US", ferr.RefLang)
+	}
+	assert.Contains(t, err.Error(), "i18n: 4 catalog problems:")
+	assert.Contains(t, err.Error(), zhFile+`: zh-CN: key "1000": message takes 0 arguments but en-US takes 1`)
+	assert.Contains(t, err.Error(), frFile+`: fr: key "0": empty message`)
+
+	// Without strict mode the same files load
+	_, err = New(WithLangDir(dir))
+	assert.NoError(t, err)
+
+	_, err = New(WithLangDir(dir), WithDefaultLang("de"), WithStrict(true))
+	assert.True(t, errors.Is(err, ErrDefaultLangMissing))
+}
+
+func TestWithStrictPluralForms(t *testing.T) {
+	dir := writeLangDir(t, map[string]string{
+		"en-US.json": `{"1": {"one": "One file", "other": "%s files"}}`,
+		"ru-RU.json": `{"1": {"one": "%s файл", "few": "%s файла", "many": "%s файлов", "other": "%s файла"}}`,
+	})
+	m, err := New(WithLangDir(dir), WithDefaultLang("en-US"), WithStrict(true))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "21 файл", m.TransPlural("ru-RU", "1", 21, "21"))
+	}
+
+	// A form taking more arguments than any form of the reference is rejected
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "ru-RU.json"), []byte(`{"1": {"one": "%s файл %s", "other": "%s файла"}}`), 0o644))
+	var cerr *CatalogError
+	if assert.True(t, errors.As(m.Reload(), &cerr)) && assert.Len(t, cerr.Problems, 1) {
+		assert.Equal(t, "1.one", cerr.Problems[0].Key)
+	}
+}
+
+func TestWithStrictReload(t *testing.T) {
+	dir := writeLangDir(t, map[string]string{
+		"en-US.json": `{"0": "ok", "500": "fail"}`,
+		"zh-CN.json": `{"0": "成功", "500": "失败"}`,
+	})
+	m, err := New(WithLangDir(dir), WithStrict(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A reload with problems is rejected
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "zh-CN.json"), []byte(`{"0": "成功"}`), 0o644))
+	assert.True(t, errors.Is(m.Reload(), ErrKeyMissing))
+	assert.Equal(t, "失败", m.Trans("zh-CN", "500"))
+
+	// Messages of other sources are reported by source name
+	_, err = New(WithSources(DirSource(dir), MapSource("overrides", map[string]map[string]string{"zh-CN": {"500": ""}})), WithStrict(true))
+	var cerr *CatalogError
+	if assert.True(t, errors.As(err, &cerr)) {
+		assert.Equal(t, &CatalogProblem{Lang: "zh-CN", Key: "500", File: "overrides", Err: ErrEmptyMessage}, cerr.Problems[0])
+	}
+}
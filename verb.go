@@ -0,0 +1,320 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+type (
+	// FormatError describes a printf message whose verbs don't match the
+	// arguments it is rendered with, or the verbs of the same message in
+	// another language.
+	FormatError struct {
+		Lang    string // Language of the message
+		Key     string // Message code
+		Msg     string // Message template
+		Verbs   int    // Number of arguments the message's verbs consume
+		Args    int    // Number of arguments given, or consumed by the reference message
+		RefLang string // Reference language of a mismatch between languages, "" at render time
+		Err     error  // Syntax error of the message, if any
+	}
+
+	// FormatErrorHandler is called for every FormatError found when loading
+	// language files or rendering messages.
+	FormatErrorHandler func(err *FormatError)
+)
+
+// WithFormatErrorHandler returns an Option that sets the handler notified
+// of format problems: messages whose verbs are malformed or differ between
+// languages when the language files are loaded, and messages rendered with
+// the wrong number of parameters.
+//
+// Parameters:
+//   - h: The handler to notify
+//
+// Returns:
+//   - Option: A function that sets the format error handler in the options
+//
+// Example:
+//
+//	i18n.New(i18n.WithFormatErrorHandler(func(err *i18n.FormatError) {
+//	    log.Println(err)
+//	}))
+func WithFormatErrorHandler(h FormatErrorHandler) Option {
+	return func(o *option) {
+		o.formatErrorHandler = h
+	}
+}
+
+// Error implements the error interface.
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("i18n: %s: key %q: %s", e.Lang, e.Key, e.detail())
+}
+
+// detail describes the problem without the language and key.
+//
+// Returns:
+//   - string: The description of the problem
+func (e *FormatError) detail() string {
+	switch {
+	case e.Err != nil:
+		return e.Err.Error()
+	case e.RefLang != "":
+		return fmt.Sprintf("message takes %d arguments but %s takes %d", e.Verbs, e.RefLang, e.Args)
+	default:
+		return fmt.Sprintf("message takes %d arguments but %d were given", e.Verbs, e.Args)
+	}
+}
+
+// Unwrap returns the syntax error of the message, if any.
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// countVerbs returns the number of arguments consumed by the fmt verbs of
+// a message, honoring "%%", "*" widths and precisions, and explicit
+// argument indexes such as "%[2]s".
+//
+// Parameters:
+//   - msg: The message template
+//
+// Returns:
+//   - int: The number of arguments the message consumes
+//   - error: An error if a verb is malformed
+func countVerbs(msg string) (int, error) {
+	argNum, max := 0, 0
+	use := func() {
+		argNum++
+		if argNum > max {
+			max = argNum
+		}
+	}
+
+	for i := 0; i < len(msg); i++ {
+		if msg[i] != '%' {
+			continue
+		}
+
+		i++
+		// Flags
+		for i < len(msg) && (msg[i] == '+' || msg[i] == '-' || msg[i] == '#' || msg[i] == ' ' || msg[i] == '0') {
+			i++
+		}
+
+		// Argument index, width, precision and a second index before the verb
+		for i < len(msg) {
+			switch c := msg[i]; {
+			case c == '[':
+				end := i + 1
+				n := 0
+				for end < len(msg) && msg[end] >= '0' && msg[end] <= '9' {
+					n = n*10 + int(msg[end]-'0')
+					end++
+				}
+				if end >= len(msg) || msg[end] != ']' || n == 0 {
+					return 0, fmt.Errorf("bad argument index at offset %d", i)
+				}
+				argNum = n - 1
+				i = end + 1
+				continue
+			case c == '*':
+				use()
+				i++
+				continue
+			case c == '.' || (c >= '0' && c <= '9'):
+				i++
+				continue
+			}
+			break
+		}
+
+		if i >= len(msg) {
+			return 0, errors.New("missing verb at end of message")
+		}
+		if msg[i] == '%' {
+			continue
+		}
+		use()
+	}
+
+	return max, nil
+}
+
+// checkArgs checks that a message can be rendered with the given number
+// of positional parameters. Only printf messages are checked, and messages
+// rendered without parameters are returned as-is so they are always safe.
+//
+// Parameters:
+//   - cat: The catalog of the message
+//   - lang: The language of the message
+//   - code: The message code
+//   - msg: The message template
+//   - params: The number of positional parameters
+//   - extra: Whether surplus parameters are allowed, e.g. for plural forms that omit the count
+//
+// Returns:
+//   - int: The number of parameters the message consumes
+//   - *FormatError: The problem found, nil if the message can be rendered
+func (m *Manager) checkArgs(cat *catalog, lang string, code string, msg string, params int, extra bool) (int, *FormatError) {
+	if params == 0 || m.formatOf(cat.langs[lang]) != PrintfFormat {
+		return params, nil
+	}
+
+	verbs, err := countVerbs(msg)
+	if err != nil {
+		return 0, &FormatError{Lang: lang, Key: code, Msg: msg, Err: err}
+	}
+	if verbs == params || (extra && verbs < params) {
+		return verbs, nil
+	}
+
+	return 0, &FormatError{Lang: lang, Key: code, Msg: msg, Verbs: verbs, Args: params}
+}
+
+// reportFormatError logs a format problem and notifies the configured
+// format error handler, if any.
+//
+// Parameters:
+//   - ctx: The context of the problem, such as the gin context of a response
+//   - err: The format problem to report
+func (m *Manager) reportFormatError(ctx context.Context, err *FormatError) {
+	m.log(ctx, slog.LevelWarn, logFormatError, append(m.logKey(err.Lang, err.Key), slog.Any("error", err))...)
+	if m.opt.formatErrorHandler != nil {
+		m.opt.formatErrorHandler(err)
+	}
+}
+
+// CheckFormats checks the printf messages of all loaded languages and
+// returns every malformed message, and every message whose verbs consume a
+// different number of arguments than the same key in the reference
+// language. The reference is the default language if it has the key,
+// otherwise the first language that has it in alphabetical order. Plural
+// forms may omit the count, so a plural form is only reported if it takes
+// more arguments than every plural form of the message in the reference.
+//
+// Returns:
+//   - []*FormatError: The problems found, sorted by key and language
+//
+// Example:
+//
+//	for _, err := range manager.CheckFormats() {
+//	    log.Println(err)
+//	}
+func (m *Manager) CheckFormats() []*FormatError {
+	return m.checkFormats(m.langList())
+}
+
+// checkFormats checks the printf messages of a language list, see
+// CheckFormats.
+//
+// Parameters:
+//   - langList: A map of language codes to their message maps
+//
+// Returns:
+//   - []*FormatError: The problems found, sorted by key and language
+func (m *Manager) checkFormats(langList map[string]map[string]string) []*FormatError {
+	langs := make([]string, 0, len(langList))
+	for lang := range langList {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	// Count the verbs of every printf message, keyed by code and language,
+	// and the most verbs among the plural forms of each message
+	counts := make(map[string]map[string]int)
+	forms := make(map[string]map[string]int)
+	var problems []*FormatError
+	for _, lang := range langs {
+		if m.formatOf(langList[lang]) != PrintfFormat {
+			continue
+		}
+
+		for key, msg := range langList[lang] {
+			if isMetaKey(key) {
+				continue
+			}
+
+			verbs, err := countVerbs(msg)
+			if err != nil {
+				problems = append(problems, &FormatError{Lang: lang, Key: key, Msg: msg, Err: err})
+				continue
+			}
+			if counts[key] == nil {
+				counts[key] = make(map[string]int)
+			}
+			counts[key][lang] = verbs
+
+			code, _, _ := cutPluralCategory(key)
+			if forms[code] == nil {
+				forms[code] = make(map[string]int)
+			}
+			if n, ok := forms[code][lang]; !ok || verbs > n {
+				forms[code][lang] = verbs
+			}
+		}
+	}
+
+	for key, byLang := range counts {
+		// Plural forms may omit the count, so a form only needs no more
+		// verbs than the reference's forms take
+		code, _, plural := cutPluralCategory(key)
+		if plural {
+			byLang = forms[code]
+		}
+		ref := m.referenceLang(langs, byLang)
+
+		for lang, verbs := range counts[key] {
+			if verbs == byLang[ref] || (plural && verbs < byLang[ref]) {
+				continue
+			}
+			problems = append(problems, &FormatError{
+				Lang:    lang,
+				Key:     key,
+				Msg:     langList[lang][key],
+				Verbs:   verbs,
+				Args:    byLang[ref],
+				RefLang: ref,
+			})
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].Key != problems[j].Key {
+			return problems[i].Key < problems[j].Key
+		}
+		return problems[i].Lang < problems[j].Lang
+	})
+
+	return problems
+}
+
+// referenceLang returns the language messages are compared with: the
+// default language if it has the message, otherwise the first language
+// that has it.
+//
+// Parameters:
+//   - langs: The language codes, sorted
+//   - byLang: The verb counts of the message, keyed by language
+//
+// Returns:
+//   - string: The reference language code
+func (m *Manager) referenceLang(langs []string, byLang map[string]int) string {
+	def := m.DefaultLang()
+	if _, ok := byLang[def]; ok {
+		return def
+	}
+	for _, lang := range langs {
+		if _, ok := byLang[lang]; ok {
+			return lang
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,89 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCountVerbs(t *testing.T) {
+	tests := []struct {
+		msg   string
+		want  int
+		isErr bool
+	}{
+		{msg: "no verbs", want: 0},
+		{msg: "100%% sure", want: 0},
+		{msg: "Hello,%s!Your account is:%s", want: 2},
+		{msg: "%-10s|%5.2f|%+d", want: 3},
+		{msg: "%*d", want: 2},
+		{msg: "%[2]s %[1]s", want: 2},
+		{msg: "%[3]s", want: 3},
+		{msg: "%[1]s %s %s", want: 3},
+		{msg: "trailing %", isErr: true},
+		{msg: "%[x]s", isErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := countVerbs(tt.msg)
+		if tt.isErr {
+			assert.Error(t, err, tt.msg)
+			continue
+		}
+		assert.NoError(t, err, tt.msg)
+		assert.Equal(t, tt.want, got, tt.msg)
+	}
+}
+
+func TestSafeRendering(t *testing.T) {
+	var reported []*FormatError
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{"1": "Hello, %s! Account: %s", "2": "No verbs", "3": {"one": "One file", "other": "%s files"}, "4": "Hi %s"}`,
+		"zh-CN.json": `{"1": "你好, %s!", "2": "没有", "4": "你好 %s %s"}`,
+	}, WithFormatErrorHandler(func(err *FormatError) {
+		reported = append(reported, err)
+	}))
+
+	// Load-time mismatches between languages, with en-US as the reference
+	if assert.Len(t, reported, 2) {
+		assert.Equal(t, "zh-CN", reported[0].Lang)
+		assert.Equal(t, "1", reported[0].Key)
+		assert.Equal(t, "en-US", reported[0].RefLang)
+		assert.Equal(t, "4", reported[1].Key)
+	}
+	reported = nil
+
+	// Too few parameters falls back to a language that can render the message
+	assert.Equal(t, "Hello, Seakee! Account: 188", m.Trans("zh-CN", "1", "Seakee", "188"))
+	if assert.Len(t, reported, 1) {
+		assert.Equal(t, &FormatError{Lang: "zh-CN", Key: "1", Msg: "你好, %s!", Verbs: 1, Args: 2}, reported[0])
+	}
+
+	// Surplus parameters with no renderable fallback return the unformatted message
+	reported = nil
+	msg, served := m.TransWithLang("zh-CN", "2", "x")
+	assert.Equal(t, "没有", msg)
+	assert.Equal(t, "zh-CN", served)
+	assert.Len(t, reported, 2)
+
+	// Missing parameters never produce %!s(MISSING)
+	assert.Equal(t, "Hi %s", m.Trans("en-US", "4", "a", "b", "c"))
+
+	// Plural forms may omit the count
+	reported = nil
+	assert.Equal(t, "One file", m.TransPlural("en-US", "3", 1, "1"))
+	assert.Equal(t, "2 files", m.TransPlural("en-US", "3", 2, "2"))
+	assert.Empty(t, reported)
+}
+
+func TestCheckFormatsPluralForms(t *testing.T) {
+	m := newTestManager(t, map[string]string{
+		"en-US.json": `{"1": {"one": "One file", "other": "%s files"}}`,
+		"ru-RU.json": `{"1": {"one": "%s файл", "few": "%s файла", "many": "%s файлов %s", "other": "файлы"}}`,
+	}, WithDefaultLang("en-US"))
+
+	// Forms are compared with the most arguments any form of en-US takes
+	problems := m.CheckFormats()
+	if assert.Len(t, problems, 1) {
+		assert.Equal(t, &FormatError{Lang: "ru-RU", Key: "1.many", Msg: "%s файлов %s", Verbs: 2, Args: 1, RefLang: "en-US"}, problems[0])
+	}
+}
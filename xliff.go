@@ -0,0 +1,536 @@
+// Copyright 2024 Seakee. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"golang.org/x/text/feature/plural"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// XLIFFVersion is a version of the XLIFF exchange format.
+type XLIFFVersion string
+
+const (
+	// XLIFF12 is XLIFF 1.2
+	XLIFF12 XLIFFVersion = "1.2"
+	// XLIFF20 is XLIFF 2.0
+	XLIFF20 XLIFFVersion = "2.0"
+
+	// xliffOriginal identifies the catalog in the file element of exported documents
+	xliffOriginal = "i18n"
+)
+
+type (
+	// xliff12Doc is an XLIFF 1.2 document, for export.
+	xliff12Doc struct {
+		XMLName xml.Name `xml:"xliff"`
+		Version string   `xml:"version,attr"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		File    struct {
+			Original       string        `xml:"original,attr"`
+			SourceLanguage string        `xml:"source-language,attr"`
+			TargetLanguage string        `xml:"target-language,attr"`
+			Datatype       string        `xml:"datatype,attr"`
+			Units          []xliff12Unit `xml:"body>trans-unit"`
+		} `xml:"file"`
+	}
+
+	// xliff12Unit is an XLIFF 1.2 translation unit.
+	xliff12Unit struct {
+		ID     string `xml:"id,attr"`
+		Space  string `xml:"http://www.w3.org/XML/1998/namespace space,attr,omitempty"`
+		Source string `xml:"source"`
+		Target string `xml:"target,omitempty"`
+		Note   string `xml:"note,omitempty"`
+	}
+
+	// xliff20Doc is an XLIFF 2.0 document, for export.
+	xliff20Doc struct {
+		XMLName xml.Name `xml:"xliff"`
+		Version string   `xml:"version,attr"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		SrcLang string   `xml:"srcLang,attr"`
+		TrgLang string   `xml:"trgLang,attr"`
+		File    struct {
+			ID    string        `xml:"id,attr"`
+			Units []xliff20Unit `xml:"unit"`
+		} `xml:"file"`
+	}
+
+	// xliff20Unit is an XLIFF 2.0 unit with a single segment.
+	xliff20Unit struct {
+		ID      string      `xml:"id,attr"`
+		Notes   *xliffNotes `xml:"notes,omitempty"`
+		Segment struct {
+			Source xliffText  `xml:"source"`
+			Target *xliffText `xml:"target,omitempty"`
+		} `xml:"segment"`
+	}
+
+	// xliffNotes are the notes of an XLIFF 2.0 unit, which must hold at
+	// least one note if present.
+	xliffNotes struct {
+		Notes []string `xml:"note"`
+	}
+
+	// xliffText is the text of a source or target element.
+	xliffText struct {
+		Space string `xml:"http://www.w3.org/XML/1998/namespace space,attr,omitempty"`
+		Text  string `xml:",chardata"`
+	}
+
+	// xliffInput is an XLIFF 1.2 or 2.0 document, for import. Elements
+	// are matched by local name so both namespaces are accepted.
+	xliffInput struct {
+		XMLName xml.Name `xml:"xliff"`
+		TrgLang string   `xml:"trgLang,attr"`
+		Files   []struct {
+			TargetLanguage string `xml:"target-language,attr"`
+			TransUnits     []struct {
+				ID     string `xml:"id,attr"`
+				Target string `xml:"target"`
+			} `xml:"body>trans-unit"`
+			Units []xliffInputUnit `xml:"unit"`
+			// Units may be nested in groups at any depth in XLIFF 2.0
+			Groups []xliffInputGroup `xml:"group"`
+		} `xml:"file"`
+	}
+
+	// xliffInputGroup is an XLIFF 2.0 group of units, for import.
+	xliffInputGroup struct {
+		Units  []xliffInputUnit  `xml:"unit"`
+		Groups []xliffInputGroup `xml:"group"`
+	}
+
+	// xliffInputUnit is an XLIFF 2.0 unit, for import. The targets of
+	// its segments are joined.
+	xliffInputUnit struct {
+		ID       string `xml:"id,attr"`
+		Segments []struct {
+			Target string `xml:"target"`
+		} `xml:"segment"`
+	}
+)
+
+// ExportXLIFF writes the messages of a source language and their
+// translations in a target language as an XLIFF document, so they can be
+// translated with CAT tools. Each message code is a unit. Plural messages
+// get one unit per plural category of the target language, such as
+// "1001.few", whose source is the matching form of the source language, or
+// its "other" form. A message is annotated with the note stored under its
+// code prefixed by "@" in the source language, e.g. "@1000". Messages
+// missing from the target language are exported without a target.
+//
+// Parameters:
+//   - w: The writer to write the document to
+//   - version: The XLIFF version, XLIFF12 or XLIFF20
+//   - srcLang: The source language, which must be loaded
+//   - tgtLang: The target language, which may be a new language
+//
+// Returns:
+//   - error: An error if the version is unsupported, the source language
+//     is not loaded or writing fails
+//
+// Example:
+//
+//	f, _ := os.Create("zh-CN.xlf")
+//	defer f.Close()
+//	err := manager.ExportXLIFF(f, i18n.XLIFF12, "en-US", "zh-CN")
+func (m *Manager) ExportXLIFF(w io.Writer, version XLIFFVersion, srcLang string, tgtLang string) error {
+	if version != XLIFF12 && version != XLIFF20 {
+		return fmt.Errorf("unsupported XLIFF version %q", version)
+	}
+
+	langList := m.langList()
+	src, ok := langList[srcLang]
+	if !ok {
+		return fmt.Errorf("source language %q is not loaded", srcLang)
+	}
+	tgt := langList[tgtLang]
+
+	// Collect the units: plain messages as-is, plural messages by target category
+	type unit struct{ id, source, target, note string }
+	var units []unit
+	plurals := make(map[string]bool)
+	for code, msg := range src {
+		if isMetaKey(code) {
+			continue
+		}
+
+		base, _, found := cutPluralCategory(code)
+		if !found {
+			units = append(units, unit{code, msg, tgt[code], src["@"+code]})
+			continue
+		}
+		if plurals[base] {
+			continue
+		}
+		plurals[base] = true
+
+		for _, category := range pluralCategoriesOf(tgtLang) {
+			id := base + pluralSep + category
+			source, ok := src[id]
+			if !ok {
+				source, ok = src[base+pluralSep+pluralForms[plural.Other]]
+			}
+			if ok {
+				units = append(units, unit{id, source, tgt[id], src["@"+base]})
+			}
+		}
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].id < units[j].id })
+
+	var doc interface{}
+	if version == XLIFF12 {
+		d := &xliff12Doc{Version: string(version), Xmlns: "urn:oasis:names:tc:xliff:document:1.2"}
+		d.File.Original, d.File.SourceLanguage, d.File.TargetLanguage, d.File.Datatype = xliffOriginal, srcLang, tgtLang, "plaintext"
+		for _, u := range units {
+			d.File.Units = append(d.File.Units, xliff12Unit{ID: u.id, Space: "preserve", Source: u.source, Target: u.target, Note: u.note})
+		}
+		doc = d
+	} else {
+		d := &xliff20Doc{Version: string(version), Xmlns: "urn:oasis:names:tc:xliff:document:2.0", SrcLang: srcLang, TrgLang: tgtLang}
+		d.File.ID = xliffOriginal
+		for _, u := range units {
+			x := xliff20Unit{ID: u.id}
+			if u.note != "" {
+				x.Notes = &xliffNotes{Notes: []string{u.note}}
+			}
+			x.Segment.Source = xliffText{Space: "preserve", Text: u.source}
+			if u.target != "" {
+				x.Segment.Target = &xliffText{Space: "preserve", Text: u.target}
+			}
+			d.File.Units = append(d.File.Units, x)
+		}
+		doc = d
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// ImportXLIFF reads the translations of an XLIFF 1.2 or 2.0 document and
+// merges them into the JSON language file of its target language, creating
+// the file if needed, then reloads the language files. Units without a
+// target are skipped, and plural units such as "1001.few" are stored in the
+// plural object of their code. If the reload rejects the merged messages,
+// the previous file is restored. The file is written to the directory of the
+// highest precedence source loading language files from disk (see
+// WithLangDir and DirSource), which is required to import.
+//
+// Parameters:
+//   - r: The reader to read the document from
+//
+// Returns:
+//   - error: An error if the document is invalid, the language file cannot
+//     be written, or the new language files are rejected
+//
+// Example:
+//
+//	f, _ := os.Open("zh-CN.xlf")
+//	defer f.Close()
+//	err := manager.ImportXLIFF(f)
+func (m *Manager) ImportXLIFF(r io.Reader) error {
+	lang, messages, err := readXLIFF(r)
+	if err != nil {
+		return err
+	}
+
+	// Imports are serialized with loads, so concurrent imports neither
+	// lose updates nor restore over each other
+	m.loadMu.Lock()
+	restore, err := m.writeLangFile(lang, messages)
+	if err != nil {
+		m.loadMu.Unlock()
+		return err
+	}
+
+	// Put the previous file back if the import is rejected, so the next
+	// start does not fail on it
+	err = m.loadLocked()
+	if err != nil {
+		if rerr := restore(); rerr != nil {
+			err = errors.Join(err, rerr)
+		}
+	}
+	m.loadMu.Unlock()
+	m.reloaded(err)
+
+	return err
+}
+
+// readXLIFF reads the target language and the translations of an XLIFF
+// 1.2 or 2.0 document.
+//
+// Parameters:
+//   - r: The reader to read the document from
+//
+// Returns:
+//   - string: The target language
+//   - map[string]string: The translations keyed by unit ID
+//   - error: An error if the document is invalid
+func readXLIFF(r io.Reader) (string, map[string]string, error) {
+	var doc xliffInput
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return "", nil, fmt.Errorf("invalid XLIFF document: %w", err)
+	}
+
+	lang := doc.TrgLang
+	messages := make(map[string]string)
+	add := func(id string, target string) {
+		if id != "" && target != "" {
+			messages[id] = target
+		}
+	}
+
+	var addGroup func(units []xliffInputUnit, groups []xliffInputGroup)
+	addGroup = func(units []xliffInputUnit, groups []xliffInputGroup) {
+		for _, u := range units {
+			var target strings.Builder
+			for _, s := range u.Segments {
+				target.WriteString(s.Target)
+			}
+			add(u.ID, target.String())
+		}
+		for _, g := range groups {
+			addGroup(g.Units, g.Groups)
+		}
+	}
+
+	for _, f := range doc.Files {
+		if lang == "" {
+			lang = f.TargetLanguage
+		} else if f.TargetLanguage != "" && f.TargetLanguage != lang {
+			return "", nil, fmt.Errorf("invalid XLIFF document: mixed target languages %q and %q", lang, f.TargetLanguage)
+		}
+		for _, u := range f.TransUnits {
+			add(u.ID, u.Target)
+		}
+		addGroup(f.Units, f.Groups)
+	}
+
+	if lang == "" {
+		return "", nil, errors.New("invalid XLIFF document: missing target language")
+	}
+	if !isLangTag(lang) {
+		return "", nil, fmt.Errorf("invalid XLIFF document: invalid target language %q", lang)
+	}
+
+	return lang, messages, nil
+}
+
+// writeLangFile merges messages into the JSON language file of a language
+// in the language directory, creating it if needed. Messages whose code
+// ends with a plural category are stored in the plural object of their
+// code, and messages of an existing nested object are stored in it. The
+// file is replaced atomically so a watcher never reads it half written.
+//
+// Parameters:
+//   - lang: The language of the file
+//   - messages: The messages to merge, keyed by code
+//
+// Returns:
+//   - func() error: A function restoring the previous file, or removing a created one
+//   - error: An error if the file cannot be read or written
+func (m *Manager) writeLangFile(lang string, messages map[string]string) (func() error, error) {
+	dir := m.diskDir()
+	if dir == "" {
+		return nil, errors.New("language files loaded from a file system cannot be written")
+	}
+
+	path, namespace, err := findLangFile(dir, lang, m.opt.keySep)
+	if err != nil {
+		return nil, err
+	}
+	if !m.opt.namespaces {
+		namespace = ""
+	}
+
+	raw := make(map[string]interface{})
+	var restore func() error
+	if path == "" {
+		path = filepath.Join(dir, lang+".json")
+		if filepath.Dir(path) != filepath.Clean(dir) {
+			return nil, fmt.Errorf("%s: invalid language code", lang)
+		}
+		restore = func() error { return os.Remove(path) }
+	} else {
+		if !strings.EqualFold(filepath.Ext(path), ".json") {
+			return nil, fmt.Errorf("%s: only JSON language files can be written", path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		restore = func() error { return writeFile(path, data) }
+	}
+
+	for code, msg := range messages {
+		// Codes of a namespaced file are stored without their namespace
+		if namespace != "" {
+			if !strings.HasPrefix(code, namespace+m.opt.keySep) {
+				return nil, fmt.Errorf("%s: key %q is not in namespace %q", path, code, namespace)
+			}
+			code = code[len(namespace)+len(m.opt.keySep):]
+		}
+
+		base, category, found := cutPluralCategory(code)
+		if !found {
+			obj, key := locateKey(raw, code, m.opt.keySep)
+			obj[key] = msg
+			continue
+		}
+
+		obj, key := locateKey(raw, base, m.opt.keySep)
+		forms, ok := obj[key].(map[string]interface{})
+		if !ok {
+			forms = make(map[string]interface{})
+			obj[key] = forms
+		}
+		forms[category] = msg
+	}
+
+	if err = writeJSONFile(path, raw); err != nil {
+		return nil, err
+	}
+
+	return restore, nil
+}
+
+// findLangFile returns the path and the namespace of the language file of
+// a language in a directory, or "" if there is none. A language split
+// across several files cannot be written, since it is ambiguous which file
+// a message belongs to.
+//
+// Parameters:
+//   - dir: The language directory
+//   - lang: The language code
+//   - sep: The separator joining the parts of namespaces
+//
+// Returns:
+//   - string: The path of the language file, "" if not found
+//   - string: The namespace of the language file (see langPath)
+//   - error: An error if the directory cannot be walked or the language is split across files
+func findLangFile(dir string, lang string, sep string) (string, string, error) {
+	var found, namespace string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if _, ok := decoderFor(d.Name()); !ok || d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		l, ns, err := langPath(filepath.ToSlash(rel), sep)
+		if err != nil {
+			return err
+		}
+		if l != lang {
+			return nil
+		}
+		if found != "" {
+			return fmt.Errorf("language %q is split across %s and %s", lang, found, path)
+		}
+		found, namespace = path, ns
+		return nil
+	})
+
+	return found, namespace, err
+}
+
+// writeJSONFile writes v as indented JSON to path, through a temporary
+// file renamed over it.
+//
+// Parameters:
+//   - path: The path of the file
+//   - v: The value to write
+//
+// Returns:
+//   - error: An error if the file cannot be written
+func writeJSONFile(path string, v interface{}) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	return writeFile(path, buf.Bytes())
+}
+
+// writeFile writes data to path, through a temporary file renamed over it.
+//
+// Parameters:
+//   - path: The path of the file
+//   - data: The content of the file
+//
+// Returns:
+//   - error: An error if the file cannot be written
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// cutPluralCategory splits a catalog key into its message code and plural
+// category, e.g. "1001.one" into "1001" and "one".
+//
+// Parameters:
+//   - key: The catalog key
+//
+// Returns:
+//   - string: The message code
+//   - string: The plural category
+//   - bool: false if the key does not end with a plural category
+func cutPluralCategory(key string) (string, string, bool) {
+	i := strings.LastIndex(key, pluralSep)
+	if i <= 0 || !isPluralCategory(key[i+len(pluralSep):]) {
+		return key, "", false
+	}
+
+	return key[:i], key[i+len(pluralSep):], true
+}
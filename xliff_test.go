@@ -0,0 +1,223 @@
+package i18n
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+var xliffTestFiles = map[string]string{
+	"en-US.json": `{
+		"0": "ok",
+		"1000": "Hello, %s & <welcome>!",
+		"@1000": "Greeting after login, %s is the user name",
+		"1001": {"one": "%s message", "other": "%s messages"},
+		"@@format": "printf"
+	}`,
+	"ru.json":   `{"0": "хорошо", "1001": {"one": "%s сообщение"}}`,
+	"README.md": "Translations",
+}
+
+func TestExportXLIFF12(t *testing.T) {
+	m := newTestManager(t, xliffTestFiles, WithDefaultLang("en-US"))
+
+	var b bytes.Buffer
+	if !assert.NoError(t, m.ExportXLIFF(&b, XLIFF12, "en-US", "ru")) {
+		return
+	}
+	out := b.String()
+
+	assert.True(t, strings.HasPrefix(out, `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.Contains(t, out, `<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">`)
+	assert.Contains(t, out, `source-language="en-US" target-language="ru"`)
+	assert.Contains(t, out, `<source>Hello, %s &amp; &lt;welcome&gt;!</source>`)
+	assert.Contains(t, out, `<note>Greeting after login, %s is the user name</note>`)
+	assert.Contains(t, out, `<target>хорошо</target>`)
+
+	// Plural messages get a unit per category of the target language
+	for _, id := range []string{"1001.one", "1001.few", "1001.many", "1001.other"} {
+		assert.Contains(t, out, `<trans-unit id="`+id+`"`)
+	}
+	assert.NotContains(t, out, "@@format")
+	assert.NotContains(t, out, `id="@1000"`)
+}
+
+func TestExportXLIFF20(t *testing.T) {
+	m := newTestManager(t, xliffTestFiles, WithDefaultLang("en-US"))
+
+	var b bytes.Buffer
+	if !assert.NoError(t, m.ExportXLIFF(&b, XLIFF20, "en-US", "ja")) {
+		return
+	}
+	out := b.String()
+
+	assert.Contains(t, out, `<xliff version="2.0" xmlns="urn:oasis:names:tc:xliff:document:2.0" srcLang="en-US" trgLang="ja">`)
+	assert.Contains(t, out, `<note>Greeting after login, %s is the user name</note>`)
+	assert.Contains(t, out, `<unit id="1001.other">`)
+	assert.Equal(t, 1, strings.Count(out, "<notes>"))
+	assert.NotContains(t, out, `<unit id="1001.one">`)
+	assert.NotContains(t, out, "<target")
+
+	assert.Error(t, m.ExportXLIFF(&b, "1.0", "en-US", "ja"))
+	assert.Error(t, m.ExportXLIFF(&b, XLIFF20, "fr", "ja"))
+}
+
+func TestImportXLIFF(t *testing.T) {
+	dir := writeLangDir(t, xliffTestFiles)
+	m, err := New(WithLangDir(dir), WithDefaultLang("en-US"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Round-trip an export through a translator
+	var b bytes.Buffer
+	if !assert.NoError(t, m.ExportXLIFF(&b, XLIFF12, "en-US", "ru")) {
+		return
+	}
+	doc := strings.Replace(b.String(), "<source>Hello, %s &amp; &lt;welcome&gt;!</source>",
+		"<source>Hello, %s &amp; &lt;welcome&gt;!</source>\n<target>Привет, %s &amp; &lt;добро пожаловать&gt;!</target>", 1)
+	doc = strings.Replace(doc, `<trans-unit id="1001.few" xml:space="preserve">`+"\n        <source>%s messages</source>",
+		`<trans-unit id="1001.few" xml:space="preserve">`+"\n        <source>%s messages</source><target>%s сообщения</target>", 1)
+
+	if !assert.NoError(t, m.ImportXLIFF(strings.NewReader(doc))) {
+		return
+	}
+	assert.Equal(t, "Привет, Seakee & <добро пожаловать>!", m.Trans("ru", "1000", "Seakee"))
+	assert.Equal(t, "3 сообщения", m.TransPlural("ru", "1001", 3, "3"))
+	assert.Equal(t, "1 сообщение", m.TransPlural("ru", "1001", 1, "1"))
+	assert.Equal(t, "хорошо", m.Trans("ru", "0"))
+
+	// XLIFF 2.0 into a new language, with groups and split segments
+	err = m.ImportXLIFF(strings.NewReader(`<?xml version="1.0"?>
+<xliff version="2.0" xmlns="urn:oasis:names:tc:xliff:document:2.0" srcLang="en-US" trgLang="fr-FR">
+  <file id="i18n">
+    <unit id="0"><segment><source>ok</source><target>d'accord</target></segment></unit>
+    <group id="g">
+      <unit id="1000">
+        <segment><source>Hello, %s </source><target>Bonjour, %s </target></segment>
+        <segment><source>&amp; welcome!</source><target>et bienvenue !</target></segment>
+      </unit>
+      <unit id="500"><segment><source>fail</source></segment></unit>
+    </group>
+  </file>
+</xliff>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "d'accord", m.Trans("fr-FR", "0"))
+	assert.Equal(t, "Bonjour, Seakee et bienvenue !", m.Trans("fr-FR", "1000", "Seakee"))
+	// Units without a target are skipped
+	assert.Equal(t, "500", m.Trans("fr-FR", "500"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "fr-FR.json"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n  \"0\": \"d'accord\",\n  \"1000\": \"Bonjour, %s et bienvenue !\"\n}\n", string(data))
+	}
+}
+
+func TestImportXLIFFErrors(t *testing.T) {
+	m := newTestManager(t, map[string]string{"en-US.json": `{"0": "ok"}`, "de.yaml": "0: gut\n"})
+
+	for _, doc := range []string{
+		`not xml`,
+		`<xliff version="2.0"><file id="f"><unit id="0"><segment><target>x</target></segment></unit></file></xliff>`,
+		`<xliff version="1.2"><file target-language="de"><body><trans-unit id="0"><target>x</target></trans-unit></body></file><file target-language="fr"/></xliff>`,
+		// Only JSON language files can be written
+		`<xliff version="1.2"><file target-language="de"><body><trans-unit id="0"><target>sehr gut</target></trans-unit></body></file></xliff>`,
+	} {
+		assert.Error(t, m.ImportXLIFF(strings.NewReader(doc)), doc)
+	}
+
+	m, err := New(WithFS(fstest.MapFS{"en-US.json": {Data: []byte(`{"0": "ok"}`)}}, "."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Error(t, m.ImportXLIFF(strings.NewReader(`<xliff version="1.2"><file target-language="en-US"></file></xliff>`)))
+}
+
+func TestCutPluralCategory(t *testing.T) {
+	for key, want := range map[string][]string{
+		"1001.one":       {"1001", "one"},
+		"admin.1001.few": {"admin.1001", "few"},
+		"admin.0":        {"admin.0", ""},
+		"1000":           {"1000", ""},
+		".other":         {".other", ""},
+	} {
+		base, category, found := cutPluralCategory(key)
+		assert.Equal(t, want, []string{base, category}, key)
+		assert.Equal(t, want[1] != "", found, key)
+	}
+}
+
+func TestImportXLIFFRejected(t *testing.T) {
+	dir := writeLangDir(t, map[string]string{
+		"en-US.json": `{"0": "ok", "1000": "Hello, %s!"}`,
+		"de.json":    `{"0": "gut", "1000": "Hallo, %s!"}`,
+	})
+	m, err := New(WithLangDir(dir), WithDefaultLang("en-US"), WithStrict(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The previous file is restored
+	deFile := filepath.Join(dir, "de.json")
+	before, _ := os.ReadFile(deFile)
+	assert.Error(t, m.ImportXLIFF(strings.NewReader(`<xliff version="1.2"><file target-language="de"><body><trans-unit id="1000"><target>Hallo!</target></trans-unit></body></file></xliff>`)))
+	after, _ := os.ReadFile(deFile)
+	assert.Equal(t, string(before), string(after))
+
+	// A created file is removed
+	assert.Error(t, m.ImportXLIFF(strings.NewReader(`<xliff version="1.2"><file target-language="fr"><body><trans-unit id="0"><target>d'accord</target></trans-unit></body></file></xliff>`)))
+	_, err = os.Stat(filepath.Join(dir, "fr.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, m.Reload())
+	assert.Equal(t, "Hallo, Seakee!", m.Trans("de", "1000", "Seakee"))
+}
+
+func TestImportXLIFFTargetLanguage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "lang")
+	assert.NoError(t, os.Mkdir(dir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "en-US.json"), []byte(`{"0": "ok"}`), 0o644))
+	m, err := New(WithLangDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, lang := range []string{"../evil", "en-US/../../evil", "/tmp/evil", "not a tag"} {
+		err = m.ImportXLIFF(strings.NewReader(`<xliff version="2.0" srcLang="en-US" trgLang="` + lang + `"><file id="f"><unit id="0"><segment><source>ok</source><target>evil</target></segment></unit></file></xliff>`))
+		assert.Error(t, err, lang)
+	}
+	_, err = os.Stat(filepath.Join(dir, "..", "evil.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestImportXLIFFConcurrent(t *testing.T) {
+	dir := writeLangDir(t, map[string]string{"en-US.json": `{"0": "ok"}`, "de.json": `{"0": "gut"}`})
+	m, err := New(WithLangDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Concurrent imports of the same language keep each other's messages
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			doc := fmt.Sprintf(`<xliff version="1.2"><file target-language="de"><body><trans-unit id="%d"><target>m%d</target></trans-unit></body></file></xliff>`, 100+i, i)
+			assert.NoError(t, m.ImportXLIFF(strings.NewReader(doc)))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, fmt.Sprintf("m%d", i), m.Trans("de", fmt.Sprint(100+i)))
+	}
+}